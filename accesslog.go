@@ -0,0 +1,103 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type AccessLogFormat int
+
+const (
+	// AccessLogCommon is the Common Log Format:
+	// host ident authuser [date] "request line" status bytes
+	AccessLogCommon AccessLogFormat = iota
+	// AccessLogCombined is the Common Log Format followed by "referrer" "user agent".
+	AccessLogCombined
+)
+
+type AccessLogConfig struct {
+	// Output is where lines are written, os.Stdout by default.
+	Output io.Writer
+	Format AccessLogFormat
+}
+
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+var accessLogEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// accessLogField escapes the value for a quoted field, empty values are written as "-".
+func accessLogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return accessLogEscaper.Replace(value)
+}
+
+// formatAccessLogLine formats the line for the request, status and size are written as "-" when zero.
+func formatAccessLogLine(r *http.Request, format AccessLogFormat, at time.Time, statusCode, size int) string {
+	host := remoteIP(r)
+	username, _, _ := r.BasicAuth()
+	status, bytesSent := "-", "-"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	if size != 0 {
+		bytesSent = strconv.Itoa(size)
+	}
+
+	line := fmt.Sprintf(
+		`%s - %s [%s] "%s %s %s" %s %s`,
+		host, accessLogField(username), at.Format(accessLogTimeLayout),
+		accessLogEscaper.Replace(r.Method), accessLogEscaper.Replace(r.RequestURI), r.Proto,
+		status, bytesSent,
+	)
+	if format == AccessLogCombined {
+		line += fmt.Sprintf(` "%s" "%s"`, accessLogField(r.Referer()), accessLogField(r.UserAgent()))
+	}
+	return line + "\n"
+}
+
+// GetAccessLogMiddleware writes a line per request to cfg.Output in the Common or Combined Log Format.
+// It needs the serialized response to know its size, so it has to be placed outside
+// DataProcessingMiddleware. The size of streamed responses and the status of taken over ones
+// are unknown and written as "-".
+func GetAccessLogMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg AccessLogConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	var mu sync.Mutex
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("AccessLogMiddleware start")
+			start := time.Now()
+			ggresp, err := hFunc(ggreq)
+
+			statusCode, responseData := resolveResponse(ggresp, err)
+			size := len(responseData)
+			if ggreq.responseTakenOver {
+				statusCode, size = 0, 0
+			}
+			if ggreq.Request.Method == http.MethodHead || (err == nil && ggresp != nil && ggresp.bodyWriter != nil) {
+				size = 0
+			}
+			line := formatAccessLogLine(ggreq.Request, cfg.Format, start, statusCode, size)
+			mu.Lock()
+			_, writeErr := io.WriteString(cfg.Output, line)
+			mu.Unlock()
+			if writeErr != nil {
+				ggreq.Logger.Warn("Failed to write access log", slog.String("error", writeErr.Error()))
+			}
+
+			ggreq.Logger.Debug("AccessLogMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
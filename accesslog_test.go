@@ -0,0 +1,46 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestAccessLogMiddlewareWritesCombinedLine(t *testing.T) {
+	var out bytes.Buffer
+	u := newTestHandler(okHandler("ok"), GetAccessLogMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](AccessLogConfig{Output: &out, Format: AccessLogCombined}))
+	req := httptest.NewRequest(http.MethodGet, "/items?id=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+
+	serve(t, u, req)
+
+	linePattern := regexp.MustCompile(`^192\.0\.2\.1 - - \[[^]]+\] "GET /items\?id=1 HTTP/1\.1" 200 14 "-" "test-agent"\n$`)
+	if !linePattern.MatchString(out.String()) {
+		t.Errorf("access log line = %q", out.String())
+	}
+}
+
+func TestAccessLogMiddlewareDefaultsToStdout(t *testing.T) {
+	stdout := os.Stdout
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	os.Stdout = f
+	defer func() { os.Stdout = stdout }()
+
+	u := newTestHandler(okHandler("ok"), GetAccessLogMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](AccessLogConfig{}))
+	serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	written, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(written, []byte(`"GET / HTTP/1.1" 200`)) {
+		t.Errorf("stdout = %q, want the access log line", written)
+	}
+}
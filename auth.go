@@ -0,0 +1,55 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// PrincipalValueKey is the GGRequest.Values key the authenticated principal is stored under.
+const PrincipalValueKey = "principal"
+
+// Principal returns the principal stored by the auth middleware.
+func Principal[T any](values Values) (T, bool) {
+	return GetValue[T](values, PrincipalValueKey)
+}
+
+// BearerToken extracts the token from the "Authorization: Bearer <token>" header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// BasicAuth extracts credentials from the "Authorization: Basic <credentials>" header.
+func BasicAuth(r *http.Request) (user, pass string, ok bool) {
+	return r.BasicAuth()
+}
+
+// GetAuthMiddleware authenticates requests with authFn before calling the handler. Requests failing
+// authentication get 401, with the WWW-Authenticate header set to wwwAuthenticate when it's not empty.
+// The middleware should be placed outside DataProcessingMiddleware, so the header is not dropped.
+func GetAuthMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](authFn func(*http.Request) (any, error), wwwAuthenticate string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("AuthMiddleware start")
+			principal, err := authFn(ggreq.Request)
+			if err != nil {
+				ggreq.Logger.Info("Authentication failed", slog.String("error", err.Error()))
+				ggresp := &GGResponse[TRespBody, TErrorData]{}
+				if wwwAuthenticate != "" {
+					ggresp.Headers = map[string][]string{"WWW-Authenticate": {wwwAuthenticate}}
+				}
+				return ggresp, MiddlewareProcessingError{Message: http.StatusText(http.StatusUnauthorized), StatusCode: http.StatusUnauthorized}
+			}
+			SetValue(ggreq.Values, PrincipalValueKey, principal)
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("AuthMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
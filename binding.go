@@ -0,0 +1,167 @@
+package gogohandlers
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// bindTaggedValues fills fields of the struct pointed by dst which have the tagName tag
+// with values returned by lookup. Fields tagged with the ",required" option must be found
+// by lookup. Non-struct destinations are left untouched.
+func bindTaggedValues(dst any, tagName string, lookup func(name string) (string, bool)) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return bindStructValues(v.Elem(), tagName, lookup)
+}
+
+func bindStructValues(v reflect.Value, tagName string, lookup func(name string) (string, bool)) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := bindStructValues(v.Field(i), tagName, lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			if slices.Contains(strings.Split(options, ","), "required") {
+				return fmt.Errorf("%s %q is required", tagName, name)
+			}
+			continue
+		}
+		if err := setFieldFromString(v.Field(i), value); err != nil {
+			return fmt.Errorf("%s %q: %w", tagName, name, err)
+		}
+	}
+	return nil
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// sliceFieldKeys returns keys of the slice fields of a struct type, as named by the tag.
+func sliceFieldKeys(t reflect.Type, tagName string) map[string]bool {
+	keys := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return keys
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, hasTag := field.Tag.Lookup(tagName)
+		if !hasTag && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			maps.Copy(keys, sliceFieldKeys(field.Type, tagName))
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if field.Type.Kind() == reflect.Slice {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// splitCommaSeparated splits comma-separated values of the given keys into separate values.
+func splitCommaSeparated(values map[string][]string, keys map[string]bool) map[string][]string {
+	result := make(map[string][]string, len(values))
+	for key, keyValues := range values {
+		if !keys[key] {
+			result[key] = keyValues
+			continue
+		}
+		for _, value := range keyValues {
+			result[key] = append(result[key], strings.Split(value, ",")...)
+		}
+	}
+	return result
+}
+
+// timeConverter parses time.Time values with RFC3339 and the given layouts, in order.
+func timeConverter(layouts []string) func(string) reflect.Value {
+	layouts = append([]string{time.RFC3339}, layouts...)
+	return func(value string) reflect.Value {
+		for _, layout := range layouts {
+			parsed, err := time.Parse(layout, value)
+			if err == nil {
+				return reflect.ValueOf(parsed)
+			}
+		}
+		return reflect.Value{}
+	}
+}
@@ -0,0 +1,41 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// BufferBody reads the whole request body into memory and replaces it with a re-readable copy,
+// so middlewares needing the raw body, e.g. for signature verification, don't leave it empty
+// for DataProcessingMiddleware. Repeated calls return the same bytes and rewind the body.
+// Bodies over maxBytes are answered with 413, zero maxBytes means no limit.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) BufferBody(maxBytes int64) ([]byte, error) {
+	if ggreq.bodyBuffered {
+		ggreq.Request.Body = io.NopCloser(bytes.NewReader(ggreq.bufferedBody))
+		return ggreq.bufferedBody, nil
+	}
+	if ggreq.Request.Body == nil || ggreq.Request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body := ggreq.Request.Body
+	defer body.Close()
+	var reader io.Reader = body
+	if maxBytes > 0 {
+		reader = http.MaxBytesReader(nil, body, maxBytes)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusRequestEntityTooLarge}
+		}
+		return nil, err
+	}
+
+	ggreq.bufferedBody, ggreq.bodyBuffered = data, true
+	ggreq.Request.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const defaultMaxLoggedBodyBytes = 4096
+
+// peekRequestBody reads up to limit+1 bytes of the request body and puts them back,
+// so the body can still be consumed by the decoder.
+func peekRequestBody(r *http.Request, limit int) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	return head, err
+}
+
+// formatLoggedBody prepares a body for logging: JSON keys listed in redactFields get "***" values.
+// Bodies which can't be redacted, because they are truncated or not JSON, are not logged.
+func formatLoggedBody(body []byte, limit int, redactFields []string) string {
+	truncated := len(body) > limit
+	if len(redactFields) == 0 {
+		if truncated {
+			return string(body[:limit]) + "...(truncated)"
+		}
+		return string(body)
+	}
+	if truncated {
+		return "(omitted: too large to redact)"
+	}
+
+	var parsed any
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "(omitted: not valid JSON)"
+	}
+	redacted, err := json.Marshal(redactJSONValue(parsed, redactFields))
+	if err != nil {
+		return "(omitted: " + err.Error() + ")"
+	}
+	return string(redacted)
+}
+
+func redactJSONValue(value any, redactFields []string) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		for key, item := range typedValue {
+			redact := false
+			for _, field := range redactFields {
+				if strings.EqualFold(key, field) {
+					redact = true
+					break
+				}
+			}
+			if redact {
+				typedValue[key] = "***"
+			} else {
+				typedValue[key] = redactJSONValue(item, redactFields)
+			}
+		}
+	case []any:
+		for i, item := range typedValue {
+			typedValue[i] = redactJSONValue(item, redactFields)
+		}
+	}
+	return value
+}
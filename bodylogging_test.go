@@ -0,0 +1,103 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type loggedBodyRequest struct {
+	User struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	} `json:"user"`
+	Tokens []struct {
+		Token string `json:"token"`
+	} `json:"tokens"`
+	Padding string `json:"padding"`
+}
+
+// loggedRequestBodies serves the request with request body logging and returns the decoded
+// request and the logged request_body attributes.
+func loggedRequestBodies(t *testing.T, cfg LoggingConfig, body string) (*loggedBodyRequest, string) {
+	t.Helper()
+	var logs bytes.Buffer
+	var decoded *loggedBodyRequest
+	u := NewHandler(&testServiceProvider{}, func(ggreq *GGRequest[testServiceProvider, loggedBodyRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		decoded = ggreq.RequestData
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	}, slog.New(slog.NewJSONHandler(&logs, nil)))
+	u.Middlewares[2] = GetRequestLoggingMiddleware[testServiceProvider, loggedBodyRequest, testGetParams, testResponse, testErrorData](cfg)
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record struct {
+			RequestBody *string `json:"request_body"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("can't parse log line %q: %v", line, err)
+		}
+		if record.RequestBody != nil {
+			return decoded, *record.RequestBody
+		}
+	}
+	t.Fatalf("request body is not logged: %s", logs.String())
+	return nil, ""
+}
+
+func TestRequestBodyLogging(t *testing.T) {
+	body := `{"user":{"name":"bob","password":"hunter2"},"tokens":[{"token":"t1"},{"token":"t2"}],"padding":"` + strings.Repeat("x", 100) + `"}`
+
+	tests := []struct {
+		name       string
+		cfg        LoggingConfig
+		wantLogged string
+	}{
+		{
+			name:       "not redacted",
+			cfg:        LoggingConfig{LogRequestBody: true},
+			wantLogged: body,
+		},
+		{
+			name:       "redacted in nested objects and arrays",
+			cfg:        LoggingConfig{LogRequestBody: true, RedactFields: []string{"Password", "token", "padding"}},
+			wantLogged: `{"padding":"***","tokens":[{"token":"***"},{"token":"***"}],"user":{"name":"bob","password":"***"}}`,
+		},
+		{
+			name:       "truncated",
+			cfg:        LoggingConfig{LogRequestBody: true, MaxLoggedBodyBytes: 20},
+			wantLogged: body[:20] + "...(truncated)",
+		},
+		{
+			name:       "too large to redact",
+			cfg:        LoggingConfig{LogRequestBody: true, MaxLoggedBodyBytes: 20, RedactFields: []string{"password"}},
+			wantLogged: "(omitted: too large to redact)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, logged := loggedRequestBodies(t, tt.cfg, body)
+
+			if logged != tt.wantLogged {
+				t.Errorf("logged body = %s, want %s", logged, tt.wantLogged)
+			}
+			if decoded.User.Password != "hunter2" || len(decoded.Tokens) != 2 || decoded.Tokens[1].Token != "t2" || len(decoded.Padding) != 100 {
+				t.Errorf("handler got %+v, want the full body decoded", decoded)
+			}
+		})
+	}
+}
+
+func TestFormatLoggedBodyNotJSON(t *testing.T) {
+	if got := formatLoggedBody([]byte("password=hunter2"), 100, []string{"password"}); got != "(omitted: not valid JSON)" {
+		t.Errorf("formatLoggedBody() = %q, want the body omitted", got)
+	}
+}
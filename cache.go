@@ -0,0 +1,199 @@
+package gogohandlers
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a serialized response kept to be replayed later.
+type CachedResponse struct {
+	StatusCode int
+	Headers    map[string][]string
+	Body       []byte
+}
+
+// ResponseCache keeps responses by cache keys.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	// Set stores the response, the cache decides for how long to keep it.
+	Set(key string, resp *CachedResponse)
+}
+
+type memoryResponseEntry struct {
+	resp      *CachedResponse
+	expiresAt time.Time
+}
+
+// MemoryResponseStore keeps responses in memory for the TTL, it implements both IdempotencyStore
+// and ResponseCache. Expired entries are removed when they are accessed or when new entries are added.
+type MemoryResponseStore struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]memoryResponseEntry
+}
+
+func NewMemoryResponseStore(ttl time.Duration) *MemoryResponseStore {
+	return &MemoryResponseStore{
+		ttl:     ttl,
+		entries: make(map[string]memoryResponseEntry),
+	}
+}
+
+func (s *MemoryResponseStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+func (s *MemoryResponseStore) Set(key string, resp *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	maps.DeleteFunc(s.entries, func(_ string, entry memoryResponseEntry) bool {
+		return now.After(entry.expiresAt)
+	})
+	s.entries[key] = memoryResponseEntry{resp: resp, expiresAt: now.Add(s.ttl)}
+}
+
+// defaultCacheKey is the request URI. Requests with credentials get an empty key and are not cached,
+// so responses for one user are never served to another.
+func defaultCacheKey(r *http.Request) string {
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+		return ""
+	}
+	return r.URL.RequestURI()
+}
+
+// varyNames returns canonical names of the headers listed in the Vary header.
+func varyNames(headers map[string][]string) []string {
+	values, _ := lookupHeader(headers, "Vary")
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// variantKey extends the cache key with the request values of the headers the response varies by.
+func variantKey(key string, r *http.Request, names []string) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range names {
+		b.WriteString("\x00")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// isCacheable reports whether the response may be shared with other clients: responses setting
+// cookies, marked private or no-store, or varying by any header ("Vary: *") are not.
+func isCacheable(headers map[string][]string) bool {
+	if _, ok := lookupHeader(headers, "Set-Cookie"); ok {
+		return false
+	}
+	cacheControl, _ := lookupHeader(headers, "Cache-Control")
+	for _, value := range cacheControl {
+		for _, directive := range strings.Split(value, ",") {
+			directive, _, _ = strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(directive, "private") || strings.EqualFold(directive, "no-store") {
+				return false
+			}
+		}
+	}
+	return !slices.Contains(varyNames(headers), "*")
+}
+
+// GetCacheMiddleware caches successful GET responses in memory for the ttl, see GetCacheMiddlewareWithCache.
+func GetCacheMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](ttl time.Duration, keyFn func(*http.Request) string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return GetCacheMiddlewareWithCache[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](NewMemoryResponseStore(ttl), keyFn)
+}
+
+// GetCacheMiddlewareWithCache serves GET and HEAD requests from the cache, keyed by keyFn
+// (the request URI by default, requests with Authorization or Cookie headers are not cached).
+// An empty key makes the request bypass the cache. Responses with a Vary header are cached
+// per values of the listed request headers, e.g. per Accept-Encoding.
+//
+// Only successful responses without errors are cached. Streamed responses and responses which
+// are not meant to be shared, with Set-Cookie or Cache-Control private or no-store, are not.
+// It caches the serialized body, so it has to be placed outside DataProcessingMiddleware,
+// and inside RequestIDMiddleware not to replay request IDs.
+func GetCacheMiddlewareWithCache[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cache ResponseCache, keyFn func(*http.Request) string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if keyFn == nil {
+		keyFn = defaultCacheKey
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("CacheMiddleware start")
+			if ggreq.Request.Method != http.MethodGet && ggreq.Request.Method != http.MethodHead {
+				ggreq.Logger.Debug("CacheMiddleware finish, not cacheable method")
+				return hFunc(ggreq)
+			}
+			cacheKey := keyFn(ggreq.Request)
+			if cacheKey == "" {
+				ggreq.Logger.Debug("CacheMiddleware finish, not cacheable request")
+				return hFunc(ggreq)
+			}
+
+			// Responses with Vary are stored under the variant key, the entry under the plain
+			// key only lists the headers they vary by.
+			cached, ok := cache.Get(cacheKey)
+			if ok {
+				if names := varyNames(cached.Headers); len(names) > 0 {
+					cached, ok = cache.Get(variantKey(cacheKey, ggreq.Request, names))
+				}
+			}
+			if ok {
+				ggreq.Logger.Debug("CacheMiddleware finish, cache hit")
+				return &GGResponse[TRespBody, TErrorData]{
+					StatusCode:         cached.StatusCode,
+					Headers:            cloneHeaders(cached.Headers),
+					serializedResponse: cached.Body,
+				}, nil
+			}
+
+			ggresp, err := hFunc(ggreq)
+			if err != nil || ggresp == nil || ggresp.ErrorOccured || ggresp.bodyWriter != nil || !isCacheable(ggresp.Headers) {
+				return ggresp, err
+			}
+			statusCode, body := resolveResponse(ggresp, err)
+			if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+				return ggresp, err
+			}
+			resp := &CachedResponse{
+				StatusCode: statusCode,
+				Headers:    cloneHeaders(ggresp.Headers),
+				Body:       body,
+			}
+			if names := varyNames(ggresp.Headers); len(names) > 0 {
+				cache.Set(cacheKey, &CachedResponse{Headers: map[string][]string{"Vary": {strings.Join(names, ", ")}}})
+				cacheKey = variantKey(cacheKey, ggreq.Request, names)
+			}
+			cache.Set(cacheKey, resp)
+
+			ggreq.Logger.Debug("CacheMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func countingHandler(calls *int, headers map[string][]string) func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+	return func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		*calls++
+		return &GGResponse[testResponse, testErrorData]{
+			ResponseData: &testResponse{Value: ggreq.Request.Header.Get("Accept-Language")},
+			Headers:      cloneHeaders(headers),
+		}, nil
+	}
+}
+
+func TestCacheMiddlewareServesRepeatedRequestsFromCache(t *testing.T) {
+	calls := 0
+	u := newTestHandler(countingHandler(&calls, nil), GetCacheMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](time.Minute, nil))
+
+	first := serve(t, u, httptest.NewRequest(http.MethodGet, "/get_value/k", nil))
+	second := serve(t, u, httptest.NewRequest(http.MethodGet, "/get_value/k", nil))
+
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+	if first.Body.String() != second.Body.String() || second.Code != http.StatusOK {
+		t.Errorf("cached response = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+}
+
+func TestCacheMiddlewareSkipsPrivateResponses(t *testing.T) {
+	for name, headers := range map[string]map[string][]string{
+		"Set-Cookie":    {"Set-Cookie": {"session=1"}},
+		"private":       {"Cache-Control": {"private, max-age=60"}},
+		"no-store":      {"Cache-Control": {"no-store"}},
+		"Vary wildcard": {"Vary": {"*"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			u := newTestHandler(countingHandler(&calls, headers), GetCacheMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](time.Minute, nil))
+
+			serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+			serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if calls != 2 {
+				t.Errorf("handler called %d times, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestCacheMiddlewareSkipsRequestsWithCredentials(t *testing.T) {
+	calls := 0
+	u := newTestHandler(countingHandler(&calls, nil), GetCacheMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](time.Minute, nil))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		serve(t, u, req)
+	}
+
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestCacheMiddlewareKeysByVaryHeaders(t *testing.T) {
+	calls := 0
+	u := newTestHandler(countingHandler(&calls, map[string][]string{"Vary": {"Accept-Language"}}), GetCacheMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](time.Minute, nil))
+
+	bodies := make(map[string]string)
+	for _, lang := range []string{"en", "de", "en", "de"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := serve(t, u, req)
+		if previous, ok := bodies[lang]; ok && previous != rec.Body.String() {
+			t.Errorf("%s: body = %q, want %q", lang, rec.Body, previous)
+		}
+		bodies[lang] = rec.Body.String()
+	}
+
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+	if bodies["en"] == bodies["de"] {
+		t.Errorf("variants share the body %q", bodies["en"])
+	}
+}
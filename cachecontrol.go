@@ -0,0 +1,30 @@
+package gogohandlers
+
+import "net/http"
+
+// SetCacheControl sets the Cache-Control header of the response.
+func (ggresp *GGResponse[TRespBody, TErrorData]) SetCacheControl(directive string) {
+	ggresp.SetHeader("Cache-Control", directive)
+}
+
+// GetCacheControlMiddleware sets Cache-Control on successful responses which don't have it yet.
+func GetCacheControlMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](directive string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("CacheControlMiddleware start")
+			ggresp, err := hFunc(ggreq)
+			if err != nil || ggresp == nil || ggresp.ErrorOccured {
+				return ggresp, err
+			}
+			if statusCode, _ := resolveResponse(ggresp, err); statusCode >= http.StatusBadRequest {
+				return ggresp, err
+			}
+			if _, ok := lookupHeader(ggresp.Headers, "Cache-Control"); !ok {
+				ggresp.SetCacheControl(directive)
+			}
+
+			ggreq.Logger.Debug("CacheControlMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
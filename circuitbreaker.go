@@ -0,0 +1,112 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the breaker, 5 by default.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before letting a trial request through, 30s by default.
+	Cooldown time.Duration
+	// IsFailure decides which handler errors count as failures, all of them by default:
+	//
+	//	IsFailure: func(err error) bool {
+	//		_, ok := gogohandlers.MatchError[DatabaseError](err)
+	//		return ok
+	//	}
+	IsFailure func(err error) bool
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type circuitBreaker struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a request may proceed. In the half-open state only one trial request is let through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// GetCircuitBreakerMiddleware stops calling the handler after FailureThreshold consecutive failures
+// and answers with 503 until Cooldown passes. Then one trial request is let through: the breaker
+// closes if it succeeds and opens again otherwise. Failures are detected by errors returned by the
+// handler, so the middleware has to be placed inside ErrorHandlingMiddleware.
+func GetCircuitBreakerMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg BreakerConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(err error) bool { return true }
+	}
+	breaker := &circuitBreaker{cfg: cfg}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("CircuitBreakerMiddleware start")
+			if !breaker.allow() {
+				ggreq.Logger.Info("Circuit breaker is open")
+				return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{Message: http.StatusText(http.StatusServiceUnavailable), StatusCode: http.StatusServiceUnavailable}
+			}
+
+			// A panicking handler counts as a failure, so the half-open state is not left stuck.
+			failed := true
+			defer func() { breaker.record(failed) }()
+			ggresp, err := hFunc(ggreq)
+			failed = err != nil && cfg.IsFailure(err)
+			if failed {
+				ggreq.Logger.Debug("CircuitBreakerMiddleware failure recorded", slog.String("error", err.Error()))
+			}
+
+			ggreq.Logger.Debug("CircuitBreakerMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package gogohandlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerMiddlewareStates(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	errStorage := errors.New("storage is down")
+	errNotFound := errors.New("not found")
+	var handlerErr error
+	calls := 0
+	hFunc := GetCircuitBreakerMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](BreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         cooldown,
+		IsFailure:        func(err error) bool { return errors.Is(err, errStorage) },
+	})(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		calls++
+		return &GGResponse[testResponse, testErrorData]{}, handlerErr
+	})
+	call := func() error {
+		_, err := hFunc(&GGRequest[testServiceProvider, testRequest, testGetParams]{
+			Request: httptest.NewRequest(http.MethodGet, "/", nil),
+			Logger:  discardLogger(),
+		})
+		return err
+	}
+	isOpen := func(err error) bool {
+		mpe, ok := MatchError[MiddlewareProcessingError](err)
+		return ok && mpe.StatusCode == http.StatusServiceUnavailable
+	}
+
+	// Errors not matching IsFailure don't count.
+	handlerErr = errNotFound
+	for range 3 {
+		if err := call(); !errors.Is(err, errNotFound) {
+			t.Fatalf("closed breaker: error = %v", err)
+		}
+	}
+
+	handlerErr = errStorage
+	call()
+	call()
+	callsBefore := calls
+	if err := call(); !isOpen(err) || calls != callsBefore {
+		t.Fatalf("after 2 failures: error = %v, handler called %d times, want open", err, calls-callsBefore)
+	}
+
+	// After the cooldown a failing trial request opens the breaker again.
+	time.Sleep(cooldown)
+	if err := call(); !errors.Is(err, errStorage) {
+		t.Fatalf("half-open trial: error = %v, want the handler's one", err)
+	}
+	if err := call(); !isOpen(err) {
+		t.Fatalf("after a failed trial: error = %v, want open", err)
+	}
+
+	// A successful trial closes it.
+	time.Sleep(cooldown)
+	handlerErr = nil
+	if err := call(); err != nil {
+		t.Fatalf("half-open trial: error = %v", err)
+	}
+	handlerErr = errStorage
+	if err := call(); !errors.Is(err, errStorage) {
+		t.Errorf("closed breaker: error = %v, want the handler's one", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpenLetsOneTrialThrough(t *testing.T) {
+	breaker := &circuitBreaker{cfg: BreakerConfig{FailureThreshold: 1, Cooldown: time.Millisecond}}
+	breaker.record(true)
+	time.Sleep(2 * time.Millisecond)
+
+	if !breaker.allow() {
+		t.Fatal("trial request is not allowed after the cooldown")
+	}
+	if breaker.allow() {
+		t.Error("second request is allowed while the trial is in progress")
+	}
+}
@@ -0,0 +1,75 @@
+package gogohandlers
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// isTrustedProxy reports whether the IP matches one of trustedProxies, which are IPs or CIDR prefixes.
+func isTrustedProxy(ip string, trustedProxies []string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, trustedProxy := range trustedProxies {
+		if prefix, err := netip.ParsePrefix(trustedProxy); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+		} else if trustedAddr, err := netip.ParseAddr(trustedProxy); err == nil && trustedAddr.Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client which made the request. X-Forwarded-For and X-Real-IP are
+// only taken into account when the immediate peer is one of trustedProxies (IPs or CIDR prefixes
+// like "10.0.0.0/8"), otherwise they could be spoofed and RemoteAddr is used. X-Forwarded-For is
+// walked from the right, the first address which is not a trusted proxy is the client one.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	peer := remoteIP(r)
+	if !isTrustedProxy(peer, trustedProxies) {
+		return peer
+	}
+
+	var forwardedFor []string
+	for _, headerValue := range r.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(headerValue, ",") {
+			forwardedFor = append(forwardedFor, strings.TrimSpace(ip))
+		}
+	}
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		if _, err := netip.ParseAddr(forwardedFor[i]); err != nil {
+			break
+		}
+		if !isTrustedProxy(forwardedFor[i], trustedProxies) || i == 0 {
+			return forwardedFor[i]
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if _, err := netip.ParseAddr(realIP); err == nil {
+			return realIP
+		}
+	}
+	return peer
+}
+
+// ClientIPKey builds a rate limiting key function using ClientIP with the trusted proxies.
+func ClientIPKey(trustedProxies []string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return ClientIP(r, trustedProxies)
+	}
+}
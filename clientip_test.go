@@ -0,0 +1,148 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		headers        map[string][]string
+		trustedProxies []string
+		want           string
+	}{
+		{
+			name:       "no proxy",
+			remoteAddr: "203.0.113.7:1234",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged X-Forwarded-For from untrusted peer",
+			remoteAddr: "203.0.113.7:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-IP": {"198.51.100.2"}},
+			want:       "203.0.113.7",
+		},
+		{
+			name:           "forged X-Forwarded-For from peer outside trusted proxies",
+			remoteAddr:     "203.0.113.7:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			trustedProxies: []string{"10.0.0.1"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "single trusted proxy",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			trustedProxies: []string{"10.0.0.1"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1, 203.0.113.7", "10.0.0.2"},
+			},
+			trustedProxies: []string{"10.0.0.1", "10.0.0.2"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "all hops trusted",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			trustedProxies: []string{"10.0.0.0/8"},
+			want:           "10.0.0.3",
+		},
+		{
+			name:           "X-Real-IP without X-Forwarded-For",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        map[string][]string{"X-Real-IP": {"203.0.113.7"}},
+			trustedProxies: []string{"10.0.0.1"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "malformed headers",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"garbage"}, "X-Real-IP": {"garbage"}},
+			trustedProxies: []string{"10.0.0.1"},
+			want:           "10.0.0.1",
+		},
+		{
+			name:           "CIDR trusted proxies",
+			remoteAddr:     "10.1.2.3:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"203.0.113.7, 192.168.5.5"}},
+			trustedProxies: []string{"10.0.0.0/8", "192.168.0.0/16"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "IPv6 trusted proxy",
+			remoteAddr:     "[2001:db8::1]:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"2001:db8:ffff::7"}},
+			trustedProxies: []string{"2001:db8::1"},
+			want:           "2001:db8:ffff::7",
+		},
+		{
+			name:           "IPv6 CIDR trusted proxies",
+			remoteAddr:     "[2001:db8::1]:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"2001:db8:ffff::7, 2001:db8::2"}},
+			trustedProxies: []string{"2001:db8::/64"},
+			want:           "2001:db8:ffff::7",
+		},
+		{
+			name:           "IPv4-mapped IPv6 peer",
+			remoteAddr:     "[::ffff:10.0.0.1]:1234",
+			headers:        map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			trustedProxies: []string{"10.0.0.0/8"},
+			want:           "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+
+			if got := ClientIP(req, tt.trustedProxies); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareKeysOnClientIP(t *testing.T) {
+	h := newTestHandler(okHandler("ok"), GetRateLimitMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](
+		rate.Limit(0), 1, ClientIPKey([]string{"10.0.0.0/8"}),
+	))
+
+	request := func(proxy, forwardedFor string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = proxy + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return req
+	}
+
+	if rec := serve(t, h, request("10.0.0.1", "203.0.113.7")); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, h, request("10.0.0.2", "203.0.113.7")); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("same client through another proxy: status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec := serve(t, h, request("10.0.0.1", "203.0.113.8")); rec.Code != http.StatusOK {
+		t.Errorf("another client through the same proxy: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, h, request("192.0.2.9", "198.51.100.1")); rec.Code != http.StatusOK {
+		t.Errorf("untrusted peer with forged X-Forwarded-For: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, h, request("192.0.2.9", "198.51.100.2")); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("untrusted peer changing X-Forwarded-For: status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
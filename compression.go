@@ -0,0 +1,120 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, headerValue := range r.Header.Values("Accept-Encoding") {
+		for _, item := range strings.Split(headerValue, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), encoding) {
+				continue
+			}
+			qValue, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !ok {
+				return true
+			}
+			q, err := strconv.ParseFloat(qValue, 64)
+			return err == nil && q > 0
+		}
+	}
+	return false
+}
+
+// decompressRequestBody replaces the request body with a decompressing reader according to Content-Encoding.
+// It reports whether the body was replaced, errors are MiddlewareProcessingError.
+func decompressRequestBody(r *http.Request) (bool, error) {
+	var decompressingReader io.ReadCloser
+	var err error
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return false, nil
+	case "gzip", "x-gzip":
+		decompressingReader, err = gzip.NewReader(r.Body)
+	case "deflate":
+		decompressingReader, err = zlib.NewReader(r.Body)
+	default:
+		return false, MiddlewareProcessingError{
+			Message:    fmt.Sprintf("unsupported content encoding %q", encoding),
+			StatusCode: http.StatusUnsupportedMediaType,
+		}
+	}
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return false, MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusRequestEntityTooLarge}
+		}
+		return false, MiddlewareProcessingError{Message: "malformed request body encoding: " + err.Error(), StatusCode: http.StatusBadRequest}
+	}
+	r.Body = decompressingReader
+	return true, nil
+}
+
+// GetCompressionMiddleware gzips responses of clients accepting gzip encoding.
+// Buffered bodies smaller than minSize bytes are sent as is, streamed bodies are always compressed.
+// Empty bodies and responses without a body (204, 304 and 1xx) are never compressed.
+// The middleware has to be placed outside DataProcessingMiddleware.
+func GetCompressionMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](minSize int) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("CompressionMiddleware start")
+			ggresp, err := hFunc(ggreq)
+			if err != nil || ggresp == nil {
+				return ggresp, err
+			}
+			if _, ok := lookupHeader(ggresp.Headers, "Content-Encoding"); ok {
+				return ggresp, err
+			}
+			statusCode, body := resolveResponse(ggresp, err)
+			if statusCode < http.StatusOK || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+				return ggresp, err
+			}
+			if ggresp.bodyWriter == nil && (len(body) == 0 || len(body) < minSize) {
+				return ggresp, err
+			}
+
+			if ggresp.Headers == nil {
+				ggresp.Headers = make(map[string][]string)
+			}
+			ggresp.Headers["Vary"] = append(ggresp.Headers["Vary"], "Accept-Encoding")
+			if !acceptsEncoding(ggreq.Request, "gzip") {
+				return ggresp, err
+			}
+
+			if bodyWriter := ggresp.bodyWriter; bodyWriter != nil {
+				ggresp.bodyWriter = func(w io.Writer) error {
+					gzipWriter := gzip.NewWriter(w)
+					err := bodyWriter(gzipWriter)
+					// The writer is closed anyway, so the compressor's resources are released.
+					if closeErr := gzipWriter.Close(); err == nil {
+						err = closeErr
+					}
+					return err
+				}
+			} else {
+				var compressed bytes.Buffer
+				gzipWriter := gzip.NewWriter(&compressed)
+				if _, err := gzipWriter.Write(ggresp.serializedResponse); err != nil {
+					return ggresp, err
+				}
+				if err := gzipWriter.Close(); err != nil {
+					return ggresp, err
+				}
+				ggresp.serializedResponse = compressed.Bytes()
+			}
+			ggresp.Headers["Content-Encoding"] = []string{"gzip"}
+
+			ggreq.Logger.Debug("CompressionMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func newGzipRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	return req
+}
+
+func gunzip(t *testing.T, body io.Reader) (string, error) {
+	t.Helper()
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	return string(decompressed), err
+}
+
+func TestCompressionMiddlewareCompressesBody(t *testing.T) {
+	u := newTestHandler(okHandler("ok"), GetCompressionMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](0))
+
+	rec := serve(t, u, newGzipRequest())
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	body, err := gunzip(t, rec.Body)
+	if err != nil || body != `{"value":"ok"}` {
+		t.Errorf("body = %q, %v", body, err)
+	}
+}
+
+func TestCompressionMiddlewareSkipsResponsesWithoutBody(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *GGResponse[testResponse, testErrorData]
+	}{
+		{name: "no content", resp: &GGResponse[testResponse, testErrorData]{StatusCode: http.StatusNoContent}},
+		{name: "redirect", resp: &GGResponse[testResponse, testErrorData]{StatusCode: http.StatusFound, Headers: map[string][]string{"Location": {"/elsewhere"}}}},
+		{name: "not modified", resp: &GGResponse[testResponse, testErrorData]{StatusCode: http.StatusNotModified}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return tt.resp, nil
+			}, GetCompressionMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](0))
+
+			rec := serve(t, u, newGzipRequest())
+
+			if got := rec.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Content-Encoding = %q, want none", got)
+			}
+			if rec.Body.Len() != 0 {
+				t.Errorf("body = %q, want empty", rec.Body.String())
+			}
+		})
+	}
+}
+
+func compress(t *testing.T, encoding, body string) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&compressed)
+	case "deflate":
+		writer = zlib.NewWriter(&compressed)
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+	if _, err := io.WriteString(writer, body); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return compressed.Bytes()
+}
+
+func TestRequestDecompression(t *testing.T) {
+	const body = `{"key":"k","value":"v"}`
+	gzipped := compress(t, "gzip", body)
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+	}{
+		{name: "identity", encoding: "identity", body: []byte(body), wantStatus: http.StatusOK},
+		{name: "gzip", encoding: "gzip", body: gzipped, wantStatus: http.StatusOK},
+		{name: "x-gzip", encoding: "x-gzip", body: gzipped, wantStatus: http.StatusOK},
+		{name: "deflate", encoding: "deflate", body: compress(t, "deflate", body), wantStatus: http.StatusOK},
+		{name: "unsupported encoding", encoding: "br", body: []byte(body), wantStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed header", encoding: "gzip", body: []byte(body), wantStatus: http.StatusBadRequest},
+		{name: "corrupted stream", encoding: "gzip", body: gzipped[:len(gzipped)/2], wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: ggreq.RequestData.Value}}, nil
+			})
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+
+			rec := serve(t, u, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != `{"value":"v"}` {
+				t.Errorf("body = %s, want the decoded value echoed", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressionMiddlewareClosesGzipStreamOnError(t *testing.T) {
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		body := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("read failed")))
+		return &GGResponse[testResponse, testErrorData]{Body: body}, nil
+	}, GetCompressionMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](0))
+
+	// The failure aborts the response, what was sent before it has to be a complete gzip stream still.
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want http.ErrAbortHandler", recovered)
+			}
+		}()
+		u.ServeHTTP(rec, newGzipRequest())
+	}()
+
+	body, err := gunzip(t, rec.Body)
+	if err != nil || body != "partial" {
+		t.Errorf("body = %q, %v, want the complete gzip stream of the sent part", body, err)
+	}
+}
@@ -0,0 +1,88 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSConfig struct {
+	// AllowedOrigins contains exact origins or "*" to allow any origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func isPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// headers returns CORS response headers for the request, or nil when the origin is not allowed.
+func (cfg CORSConfig) headers(r *http.Request, preflight bool) map[string][]string {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	headers := make(map[string][]string)
+	switch {
+	case slices.Contains(cfg.AllowedOrigins, "*") && !cfg.AllowCredentials:
+		headers["Access-Control-Allow-Origin"] = []string{"*"}
+	case slices.Contains(cfg.AllowedOrigins, "*") || slices.Contains(cfg.AllowedOrigins, origin):
+		headers["Access-Control-Allow-Origin"] = []string{origin}
+		headers["Vary"] = []string{"Origin"}
+	default:
+		return nil
+	}
+	if cfg.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = []string{"true"}
+	}
+
+	if preflight {
+		if len(cfg.AllowedMethods) > 0 {
+			headers["Access-Control-Allow-Methods"] = []string{strings.Join(cfg.AllowedMethods, ", ")}
+		}
+		if len(cfg.AllowedHeaders) > 0 {
+			headers["Access-Control-Allow-Headers"] = []string{strings.Join(cfg.AllowedHeaders, ", ")}
+		}
+		if cfg.MaxAge > 0 {
+			headers["Access-Control-Max-Age"] = []string{strconv.Itoa(int(cfg.MaxAge.Seconds()))}
+		}
+	}
+	return headers
+}
+
+// GetCORSMiddleware sets CORS headers for allowed origins and answers preflight requests
+// with 204 without invoking the handler.
+func GetCORSMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg CORSConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("CORSMiddleware start")
+			if isPreflightRequest(ggreq.Request) {
+				ggreq.Logger.Debug("CORSMiddleware finish, preflight request")
+				return &GGResponse[TRespBody, TErrorData]{
+					StatusCode: http.StatusNoContent,
+					Headers:    cfg.headers(ggreq.Request, true),
+				}, nil
+			}
+
+			ggresp, err := hFunc(ggreq)
+			if ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+			}
+			if ggresp.Headers == nil {
+				ggresp.Headers = make(map[string][]string)
+			}
+			for headerName, headerValues := range cfg.headers(ggreq.Request, false) {
+				ggresp.Headers[headerName] = append(ggresp.Headers[headerName], headerValues...)
+			}
+
+			ggreq.Logger.Debug("CORSMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gogohandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type DecodeErrorKind string
+
+const (
+	DecodeErrorEmptyBody    DecodeErrorKind = "empty_body"
+	DecodeErrorTruncated    DecodeErrorKind = "truncated"
+	DecodeErrorSyntax       DecodeErrorKind = "syntax"
+	DecodeErrorType         DecodeErrorKind = "type"
+	DecodeErrorUnknownField DecodeErrorKind = "unknown_field"
+	DecodeErrorMissingField DecodeErrorKind = "missing_field"
+	DecodeErrorOther        DecodeErrorKind = "other"
+)
+
+// DecodeError reports a request body which can't be decoded. Field and Offset are set
+// when known, Err is the original decoder error.
+type DecodeError struct {
+	Kind   DecodeErrorKind
+	Field  string
+	Offset int64
+	// Expected is the Go type the field value was expected to have and Value is the kind of the
+	// JSON value sent instead, for DecodeErrorType.
+	Expected string
+	Value    string
+	Err      error
+}
+
+func (e DecodeError) Error() string {
+	switch e.Kind {
+	case DecodeErrorEmptyBody:
+		return "request body is empty"
+	case DecodeErrorTruncated:
+		return "request body is truncated"
+	case DecodeErrorSyntax:
+		return fmt.Sprintf("request body is malformed at offset %d", e.Offset)
+	case DecodeErrorType:
+		if e.Field == "" {
+			return fmt.Sprintf("request body can't be %s", e.Value)
+		}
+		return fmt.Sprintf("field %q must be %s, not %s", e.Field, e.Expected, e.Value)
+	case DecodeErrorUnknownField:
+		return fmt.Sprintf("unknown field %q", e.Field)
+	case DecodeErrorMissingField:
+		return fmt.Sprintf("field %q is required", e.Field)
+	default:
+		return fmt.Sprintf("can't decode request body: %v", e.Err)
+	}
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e DecodeError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// newDecodeError classifies errors of deserializers, encoding/json ones are recognized.
+func newDecodeError(err error) error {
+	var decodeError DecodeError
+	if errors.As(err, &decodeError) {
+		return decodeError
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return DecodeError{Kind: DecodeErrorEmptyBody, Err: err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return DecodeError{Kind: DecodeErrorTruncated, Err: err}
+	case errors.As(err, &syntaxError):
+		return DecodeError{Kind: DecodeErrorSyntax, Offset: syntaxError.Offset, Err: err}
+	case errors.As(err, &typeError):
+		return DecodeError{Kind: DecodeErrorType, Field: typeError.Field, Offset: typeError.Offset, Expected: typeError.Type.String(), Value: typeError.Value, Err: err}
+	}
+	// encoding/json has no distinct error type for unknown fields.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return DecodeError{Kind: DecodeErrorUnknownField, Field: strings.Trim(field, `"`), Err: err}
+	}
+	return DecodeError{Kind: DecodeErrorOther, Err: err}
+}
+
+// DecodeErrorHandler builds an error handler rendering DecodeError with 400, errorDataFunc
+// builds the error data from it.
+func DecodeErrorHandler[TErrorData any](errorDataFunc func(decodeError DecodeError) *TErrorData) func(err error, l *slog.Logger) (int, *TErrorData) {
+	return func(err error, l *slog.Logger) (int, *TErrorData) {
+		decodeError, ok := MatchError[DecodeError](err)
+		if !ok {
+			return 0, nil
+		}
+		return decodeError.StatusCode(), errorDataFunc(decodeError)
+	}
+}
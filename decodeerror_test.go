@@ -0,0 +1,70 @@
+package gogohandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodeErrorData struct {
+	Kind    DecodeErrorKind `json:"kind"`
+	Field   string          `json:"field"`
+	Message string          `json:"message"`
+}
+
+type numberRequest struct {
+	Key   string `json:"key,required"`
+	Count int    `json:"count"`
+}
+
+func TestDecodeErrorCategories(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		settings DataProcessingMiddlewareSettings
+		want     decodeErrorData
+	}{
+		{name: "empty body", body: "", want: decodeErrorData{Kind: DecodeErrorEmptyBody, Message: "request body is empty"}},
+		{name: "truncated", body: `{"key":`, want: decodeErrorData{Kind: DecodeErrorTruncated, Message: "request body is truncated"}},
+		{name: "syntax", body: `{"key" x}`, want: decodeErrorData{Kind: DecodeErrorSyntax, Message: "request body is malformed at offset 8"}},
+		{name: "type", body: `{"count":"three"}`, want: decodeErrorData{Kind: DecodeErrorType, Field: "count", Message: `field "count" must be int, not string`}},
+		{
+			name:     "unknown field",
+			body:     `{"other":1}`,
+			settings: DataProcessingMiddlewareSettings{ForbidUnknownKeysInBody: true},
+			want:     decodeErrorData{Kind: DecodeErrorUnknownField, Field: "other", Message: `unknown field "other"`},
+		},
+		{
+			name:     "missing field",
+			body:     `{"count":1}`,
+			settings: DataProcessingMiddlewareSettings{RejectMissingRequiredFields: true},
+			want:     decodeErrorData{Kind: DecodeErrorMissingField, Field: "key", Message: `field "key" is required`},
+		},
+	}
+	errorHandler := DecodeErrorHandler(func(decodeError DecodeError) *decodeErrorData {
+		return &decodeErrorData{Kind: decodeError.Kind, Field: decodeError.Field, Message: decodeError.Error()}
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, numberRequest, testGetParams]) (*GGResponse[testResponse, decodeErrorData], error) {
+				return nil, nil
+			}, discardLogger())
+			u.Middlewares = DefaultMiddlewares[testServiceProvider, numberRequest, testGetParams, testResponse](&tt.settings, errorHandler)
+
+			rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var got decodeErrorData
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("body %q: %v", rec.Body.String(), err)
+			}
+			if got != tt.want {
+				t.Errorf("error data = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
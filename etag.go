@@ -0,0 +1,54 @@
+package gogohandlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetETagMiddleware sets ETag on successful GET and HEAD responses and answers matching
+// If-None-Match requests with 304. It hashes the serialized body, so it has to be placed
+// outside DataProcessingMiddleware. Streamed responses are left untouched.
+func GetETagMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any]() func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("ETagMiddleware start")
+			ggresp, err := hFunc(ggreq)
+			if err != nil || ggresp == nil || ggresp.ErrorOccured || ggresp.bodyWriter != nil {
+				return ggresp, err
+			}
+			if ggreq.Request.Method != http.MethodGet && ggreq.Request.Method != http.MethodHead {
+				return ggresp, err
+			}
+			if statusCode, _ := resolveResponse(ggresp, err); statusCode != http.StatusOK {
+				return ggresp, err
+			}
+
+			hash := sha256.Sum256(ggresp.serializedResponse)
+			etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+			if ggresp.Headers == nil {
+				ggresp.Headers = make(map[string][]string)
+			}
+			ggresp.Headers["ETag"] = []string{etag}
+
+			if ifNoneMatch := ggreq.Request.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+				ggresp.StatusCode = http.StatusNotModified
+				ggresp.serializedResponse = nil
+			}
+
+			ggreq.Logger.Debug("ETagMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	u := newTestHandler(okHandler("ok"), GetETagMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]())
+	etag := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil)).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag is not set")
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    bool
+	}{
+		{name: "no If-None-Match", method: http.MethodGet, wantStatus: http.StatusOK, wantETag: true},
+		{name: "matching", method: http.MethodGet, ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "weak matching", method: http.MethodGet, ifNoneMatch: "W/" + etag, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "one of many matching", method: http.MethodGet, ifNoneMatch: `"other", ` + etag, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "any", method: http.MethodGet, ifNoneMatch: "*", wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "not matching", method: http.MethodGet, ifNoneMatch: `"other"`, wantStatus: http.StatusOK, wantETag: true},
+		{name: "HEAD matching", method: http.MethodHead, ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "POST", method: http.MethodPost, ifNoneMatch: etag, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			rec := serve(t, u, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); (got == etag) != tt.wantETag {
+				t.Errorf("ETag = %q, want it set: %v", got, tt.wantETag)
+			}
+			wantBody := `{"value":"ok"}`
+			if tt.wantStatus == http.StatusNotModified || tt.method == http.MethodHead {
+				wantBody = ""
+			}
+			if got := rec.Body.String(); got != wantBody {
+				t.Errorf("body = %q, want %q", got, wantBody)
+			}
+		})
+	}
+}
+
+func TestETagMiddlewareSkipsErrors(t *testing.T) {
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{ErrorOccured: true, ErrorData: &testErrorData{Message: "not found"}, StatusCode: http.StatusNotFound}, nil
+	}, GetETagMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", "*")
+
+	rec := serve(t, u, req)
+
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Errorf("response = %d with ETag %q, want 404 without ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
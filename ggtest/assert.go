@@ -0,0 +1,44 @@
+package ggtest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// AssertStatus fails the test if the recorded status code is not the expected one.
+func AssertStatus(t testing.TB, recorder *httptest.ResponseRecorder, code int) {
+	t.Helper()
+	if recorder.Code != code {
+		t.Errorf("status code: got %d, want %d, body: %q", recorder.Code, code, recorder.Body.String())
+	}
+}
+
+// AssertJSONBody fails the test if the recorded body doesn't decode into a value equal to expected.
+func AssertJSONBody[T any](t testing.TB, recorder *httptest.ResponseRecorder, expected *T) {
+	t.Helper()
+	assertJSON(t, "body", recorder, expected)
+}
+
+// AssertErrorData fails the test if the response is not an error response or its body doesn't
+// decode into error data equal to expected.
+func AssertErrorData[TErrorData any](t testing.TB, recorder *httptest.ResponseRecorder, expectedErrorData *TErrorData) {
+	t.Helper()
+	if recorder.Code < 400 {
+		t.Errorf("status code: got %d, want an error status", recorder.Code)
+	}
+	assertJSON(t, "error data", recorder, expectedErrorData)
+}
+
+func assertJSON[T any](t testing.TB, what string, recorder *httptest.ResponseRecorder, expected *T) {
+	t.Helper()
+	var actual T
+	if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+		t.Errorf("%s: can't decode %q: %v", what, recorder.Body.String(), err)
+		return
+	}
+	if !reflect.DeepEqual(&actual, expected) {
+		t.Errorf("%s: got %+v, want %+v", what, actual, *expected)
+	}
+}
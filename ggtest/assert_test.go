@@ -0,0 +1,94 @@
+package ggtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeTB records failures instead of failing the test it wraps.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+type testData struct {
+	Value string `json:"value"`
+}
+
+func newRecorder(code int, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(code)
+	rec.WriteString(body)
+	return rec
+}
+
+func TestAssertStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		wantFailed bool
+	}{
+		{name: "match", code: http.StatusOK},
+		{name: "mismatch", code: http.StatusNotFound, wantFailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			AssertStatus(tb, newRecorder(tt.code, ""), http.StatusOK)
+			if failed := len(tb.errors) > 0; failed != tt.wantFailed {
+				t.Errorf("failed = %v, want %v, errors: %q", failed, tt.wantFailed, tb.errors)
+			}
+		})
+	}
+}
+
+func TestAssertJSONBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFailed bool
+	}{
+		{name: "match", body: `{"value":"ok"}`},
+		{name: "mismatch", body: `{"value":"other"}`, wantFailed: true},
+		{name: "not JSON", body: `ok`, wantFailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			AssertJSONBody(tb, newRecorder(http.StatusOK, tt.body), &testData{Value: "ok"})
+			if failed := len(tb.errors) > 0; failed != tt.wantFailed {
+				t.Errorf("failed = %v, want %v, errors: %q", failed, tt.wantFailed, tb.errors)
+			}
+		})
+	}
+}
+
+func TestAssertErrorData(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		body       string
+		wantFailed bool
+	}{
+		{name: "match", code: http.StatusBadRequest, body: `{"value":"bad"}`},
+		{name: "success status", code: http.StatusOK, body: `{"value":"bad"}`, wantFailed: true},
+		{name: "body mismatch", code: http.StatusBadRequest, body: `{"value":"other"}`, wantFailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			AssertErrorData(tb, newRecorder(tt.code, tt.body), &testData{Value: "bad"})
+			if failed := len(tb.errors) > 0; failed != tt.wantFailed {
+				t.Errorf("failed = %v, want %v, errors: %q", failed, tt.wantFailed, tb.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package ggtest provides helpers for testing gogohandlers handlers.
+package ggtest
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/dmi-feo/gogohandlers"
+)
+
+// NewTestRequest builds a GGRequest populated the way middlewares would do it, so handlers
+// can be called directly. Request is a GET request to "/", replace it if the handler needs
+// a different one. Logs are discarded.
+func NewTestRequest[TServiceProvider gogohandlers.ServiceProvider, TReqBody, TGetParams any](sp *TServiceProvider, reqBody *TReqBody, getParams *TGetParams) *gogohandlers.GGRequest[TServiceProvider, TReqBody, TGetParams] {
+	if reqBody == nil {
+		reqBody = new(TReqBody)
+	}
+	if getParams == nil {
+		getParams = new(TGetParams)
+	}
+	return &gogohandlers.GGRequest[TServiceProvider, TReqBody, TGetParams]{
+		ServiceProvider: sp,
+		RequestData:     reqBody,
+		GetParams:       getParams,
+		Request:         httptest.NewRequest(http.MethodGet, "/", nil),
+		Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Values:          make(gogohandlers.Values),
+	}
+}
@@ -0,0 +1,75 @@
+package ggtest
+
+import (
+	"testing"
+
+	"github.com/dmi-feo/gogohandlers"
+)
+
+type testServiceProvider struct {
+	Greeting string
+}
+
+type testRequest struct {
+	Name string
+}
+
+type testGetParams struct {
+	Loud bool
+}
+
+type testResponse struct {
+	Greeting string
+}
+
+func greet(ggreq *gogohandlers.GGRequest[testServiceProvider, testRequest, testGetParams]) (*gogohandlers.GGResponse[testResponse, struct{}], error) {
+	ggreq.Logger.Info("Greeting", "name", ggreq.RequestData.Name)
+	gogohandlers.SetValue(ggreq.Values, "greeted", true)
+	greeting := ggreq.ServiceProvider.Greeting + ", " + ggreq.RequestData.Name
+	if ggreq.GetParams.Loud {
+		greeting += "!"
+	}
+	return &gogohandlers.GGResponse[testResponse, struct{}]{ResponseData: &testResponse{Greeting: greeting}}, nil
+}
+
+func TestNewTestRequest(t *testing.T) {
+	ggreq := NewTestRequest(&testServiceProvider{Greeting: "Hello"}, &testRequest{Name: "Bob"}, &testGetParams{Loud: true})
+
+	ggresp, err := greet(ggreq)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ggresp.ResponseData.Greeting; got != "Hello, Bob!" {
+		t.Errorf("greeting = %q, want %q", got, "Hello, Bob!")
+	}
+	if greeted, _ := gogohandlers.GetValue[bool](ggreq.Values, "greeted"); !greeted {
+		t.Error("value set by the handler is lost")
+	}
+}
+
+func TestNewTestRequestDefaults(t *testing.T) {
+	ggreq := NewTestRequest[testServiceProvider, testRequest, testGetParams](&testServiceProvider{}, nil, nil)
+
+	if ggreq.ServiceProvider == nil {
+		t.Error("ServiceProvider is nil")
+	}
+	if ggreq.RequestData == nil {
+		t.Error("RequestData is nil")
+	}
+	if ggreq.GetParams == nil {
+		t.Error("GetParams is nil")
+	}
+	if ggreq.Request == nil {
+		t.Error("Request is nil")
+	}
+	if ggreq.Logger == nil {
+		t.Error("Logger is nil")
+	}
+	if ggreq.Values == nil {
+		t.Error("Values is nil")
+	}
+	if _, err := greet(ggreq); err != nil {
+		t.Errorf("handler failed on default request: %v", err)
+	}
+}
@@ -1,17 +1,30 @@
 package gogohandlers
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"iter"
 	"log/slog"
+	"maps"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/schema"
+	"golang.org/x/text/language"
 )
 
+// MiddlewareProcessingError is rendered by ServeHTTP as a plain text response with the given status code.
 type MiddlewareProcessingError struct {
 	Message    string
 	StatusCode int
@@ -21,10 +34,18 @@ func (e MiddlewareProcessingError) Error() string {
 	return e.Message
 }
 
+type contextKey int
+
 const (
-	requestIDContextKey = "requestID"
+	requestIDContextKey contextKey = iota
 )
 
+// RequestIDFromContext returns the request ID stored by RequestIDMiddleware.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey).(string)
+	return requestID, ok
+}
+
 type ServiceProvider interface{}
 
 type GGRequest[TServiceProvider ServiceProvider, TReqBody, TGetParams any] struct {
@@ -33,15 +54,139 @@ type GGRequest[TServiceProvider ServiceProvider, TReqBody, TGetParams any] struc
 	GetParams       *TGetParams
 	Request         *http.Request
 	Logger          *slog.Logger
+	// Values lets middlewares pass data to the handler, it lives as long as the request.
+	Values Values
+	// MultipartForm is the parsed multipart/form-data body, nil for other requests.
+	// Its temporary files are removed when the handler returns.
+	MultipartForm *multipart.Form
+
+	responseWriter    http.ResponseWriter
+	responseTakenOver bool
+	bufferedBody      []byte
+	bodyBuffered      bool
+	forwardedProto    string
+}
+
+// Context returns the request context. Pass it to service provider calls, so they are cancelled
+// when the client goes away or the request times out.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) Context() context.Context {
+	return ggreq.Request.Context()
+}
+
+// TakeOverResponseWriter gives the handler direct access to the response writer, e.g. for streaming.
+// The framework doesn't write the response afterwards: the handler is responsible for the status code,
+// headers and body, while headers set on GGResponse by middlewares are not sent. Middlewares still run,
+// but they see the response only when the handler returns, i.e. after it has been sent.
+//
+// It is also the way to upgrade connections, e.g. to WebSocket. The writer supports http.Hijacker,
+// so upgraders work with it:
+//
+//	conn, err := upgrader.Upgrade(ggreq.TakeOverResponseWriter(), ggreq.Request, nil)
+//
+// The takeover is logged when it happens, as the handler may return only when the connection is closed.
+// RequestLoggingMiddleware then logs the request as finished with taken_over=true and without status.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) TakeOverResponseWriter() http.ResponseWriter {
+	ggreq.Logger.Info("Response writer taken over by the handler")
+	ggreq.responseTakenOver = true
+	return ggreq.responseWriter
+}
+
+type Values map[string]any
+
+// SetValue stores the value under the key.
+func SetValue[T any](values Values, key string, value T) {
+	values[key] = value
+}
+
+// GetValue returns the value stored under the key if it has type T.
+func GetValue[T any](values Values, key string) (T, bool) {
+	value, ok := values[key].(T)
+	return value, ok
 }
 
 type GGResponse[TRespBody, TErrorData any] struct {
-	ResponseData       *TRespBody
-	ErrorOccured       bool
-	ErrorData          *TErrorData
-	StatusCode         int
-	Headers            map[string][]string
+	ResponseData *TRespBody
+	ErrorOccured bool
+	ErrorData    *TErrorData
+	// StatusCode overrides the response status. When zero, 200 is used for
+	// successful responses and 500 for responses with ErrorOccured set.
+	StatusCode int
+	Headers    map[string][]string
+	// Body is sent as is instead of serialized ResponseData, with ContentType
+	// (application/octet-stream by default). It is closed after sending if it
+	// implements io.Closer. Ignored when ErrorOccured is set.
+	Body        io.Reader
+	ContentType string
+	// RawBody is sent as is instead of serialized ResponseData, e.g. a cached JSON blob, with
+	// ContentType (the serializer's one by default). Unlike Body, it has a known length, so
+	// middlewares like ETag and caching work with it. Ignored when ErrorOccured is set.
+	RawBody []byte
+	// Stream, when set, is sent as newline-delimited JSON (application/x-ndjson) item by item,
+	// without buffering the whole response. The status code is sent with the first item: an error
+	// yielded before it makes the response a 500, a later one aborts the connection, so the client
+	// sees the response is incomplete. Ignored when ErrorOccured is set.
+	Stream             iter.Seq2[TRespBody, error]
 	serializedResponse []byte
+	// bodyWriter, when set, writes the response body directly to the client
+	// after the status code and headers are sent.
+	bodyWriter func(w io.Writer) error
+}
+
+// SetCookie adds a Set-Cookie header, invalid cookies are skipped.
+func (ggresp *GGResponse[TRespBody, TErrorData]) SetCookie(cookie *http.Cookie) {
+	cookieValue := cookie.String()
+	if cookieValue == "" {
+		return
+	}
+	ggresp.AddHeader("Set-Cookie", cookieValue)
+}
+
+// SetLocation sets the Location header, pointing at a created resource or a redirect target.
+func (ggresp *GGResponse[TRespBody, TErrorData]) SetLocation(url string) {
+	ggresp.SetHeader("Location", url)
+}
+
+// SetHeader replaces values of the header, regardless of the key case used in Headers.
+func (ggresp *GGResponse[TRespBody, TErrorData]) SetHeader(name, value string) {
+	if ggresp.Headers == nil {
+		ggresp.Headers = make(map[string][]string)
+	}
+	for headerName := range ggresp.Headers {
+		if strings.EqualFold(headerName, name) {
+			delete(ggresp.Headers, headerName)
+		}
+	}
+	ggresp.Headers[http.CanonicalHeaderKey(name)] = []string{value}
+}
+
+// AddHeader appends the value to the header, regardless of the key case used in Headers.
+func (ggresp *GGResponse[TRespBody, TErrorData]) AddHeader(name, value string) {
+	if ggresp.Headers == nil {
+		ggresp.Headers = make(map[string][]string)
+	}
+	for headerName, headerValues := range ggresp.Headers {
+		if strings.EqualFold(headerName, name) {
+			ggresp.Headers[headerName] = append(headerValues, value)
+			return
+		}
+	}
+	ggresp.Headers[http.CanonicalHeaderKey(name)] = []string{value}
+}
+
+// isBodylessRedirect reports whether the handler asked for a redirect without providing a body.
+// Redirect targets are passed with the Location header.
+func (ggresp *GGResponse[TRespBody, TErrorData]) isBodylessRedirect() bool {
+	return !ggresp.ErrorOccured && ggresp.ResponseData == nil && ggresp.StatusCode >= 300 && ggresp.StatusCode < 400
+}
+
+// lookupHeader returns values of the header regardless of the key case used in the map.
+func lookupHeader(headers map[string][]string, name string) ([]string, bool) {
+	for headerName, headerValues := range headers {
+		if strings.EqualFold(headerName, name) {
+			return headerValues, true
+		}
+	}
+	return nil, false
 }
 
 // Waiting for https://github.com/golang/go/issues/68903
@@ -54,62 +199,279 @@ type Uitzicht[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody,
 	// Middlewares     []func(THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody]) THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody]
 	Middlewares []func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)
 	Logger      *slog.Logger
+	// SuccessStatusCode is used for successful responses which don't set StatusCode, 200 if unset.
+	SuccessStatusCode int
+	// RequestHook, when set, is called right before the handler, after all middlewares, so
+	// RequestData and GetParams are decoded and validated. It's meant to put data derived from
+	// the request, like the tenant or feature flags, into ggreq.Values.
+	RequestHook func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams])
+	// ResponseHook, when set, is called with every response right before it's written, e.g. for
+	// auditing. It gets a copy of the response, so it can't change what is sent. It's not called
+	// for responses taken over by the handler.
+	ResponseHook func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams], resp ResponseInfo)
+}
+
+// ResponseInfo is a copy of the response passed to Uitzicht.ResponseHook.
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header
+	// Body is the serialized body, nil for streamed responses, which have BodyLength -1.
+	Body       []byte
+	BodyLength int
 }
 
 func (u *Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := u.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	ggreq := &GGRequest[TServiceProvider, TReqBody, TGetParams]{
 		ServiceProvider: u.ServiceProvider,
 		RequestData:     nil,
 		GetParams:       nil,
 		Request:         r,
-		Logger:          u.Logger,
+		Logger:          logger,
+		Values:          make(Values),
+		responseWriter:  w,
 	}
 
 	theHandler := u.HandlerFunc
+	if u.RequestHook != nil {
+		theHandler = withRequestHook(theHandler, u.RequestHook)
+	}
+	if u.SuccessStatusCode != 0 {
+		theHandler = withSuccessStatusCode(theHandler, u.SuccessStatusCode)
+	}
 
 	for _, mw := range u.Middlewares {
 		theHandler = mw(theHandler)
 	}
 	ggresp, handlerErr := theHandler(ggreq)
-
-	statusCode := http.StatusOK // FIXME
-	var responseData []byte
+	if ggreq.responseTakenOver {
+		if handlerErr != nil {
+			ggreq.Logger.Warn("Handler returned error after taking over the response", slog.String("error", handlerErr.Error()))
+		}
+		return
+	}
 
 	if handlerErr != nil {
 		ggreq.Logger.Warn("Handler returned uncaught error", slog.String("error", handlerErr.Error()))
-		var mProcError MiddlewareProcessingError
-		if errors.As(handlerErr, &mProcError) {
-			statusCode = mProcError.StatusCode
-			responseData = []byte(mProcError.Message)
-		} else {
-			statusCode = http.StatusInternalServerError
-		}
-	} else {
-		responseData = ggresp.serializedResponse
-		if ggresp.StatusCode == 0 {
-			if ggresp.ErrorOccured {
-				statusCode = http.StatusInternalServerError
-			} else {
-				statusCode = http.StatusOK
+	} else if ggresp == nil {
+		ggresp = &GGResponse[TRespBody, TErrorData]{StatusCode: http.StatusNoContent}
+	}
+	statusCode, responseData := resolveResponse(ggresp, handlerErr)
+
+	if ggresp != nil {
+		for headerName, headerValues := range ggresp.Headers {
+			for _, headerValue := range headerValues {
+				w.Header().Add(headerName, headerValue)
 			}
-		} else {
-			statusCode = ggresp.StatusCode
 		}
 	}
 
-	for headerName, headerValues := range ggresp.Headers {
-		for _, headerValue := range headerValues {
-			w.Header().Set(headerName, headerValue)
+	if handlerErr != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	streaming := handlerErr == nil && ggresp.bodyWriter != nil
+	// Body is closed by the body writer, here it's closed when the writer doesn't run.
+	if ggresp != nil && (!streaming || r.Method == http.MethodHead) {
+		if closer, ok := ggresp.Body.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+	if !streaming && statusCode != http.StatusNoContent && statusCode != http.StatusNotModified {
+		w.Header().Set("Content-Length", strconv.Itoa(len(responseData)))
+	}
+
+	if u.ResponseHook != nil {
+		resp := ResponseInfo{StatusCode: statusCode, Header: w.Header().Clone(), BodyLength: -1}
+		if !streaming {
+			resp.Body, resp.BodyLength = bytes.Clone(responseData), len(responseData)
+		}
+		u.ResponseHook(ggreq, resp)
+	}
+
+	if streaming && r.Method != http.MethodHead {
+		streamWriter := &streamResponseWriter{ResponseWriter: w, statusCode: statusCode}
+		err := ggresp.bodyWriter(streamWriter)
+		switch {
+		case err == nil:
+			streamWriter.writeHeader()
+		case !streamWriter.wroteHeader:
+			ggreq.Logger.Error("Failed to stream response", slog.String("error", err.Error()))
+			w.Header().Del("Content-Encoding")
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusInternalServerError)
+		case streamWriter.writeErr != nil:
+			// The client went away, there is nobody to tell about the failure.
+			ggreq.Logger.Warn("Failed to stream response", slog.String("error", err.Error()))
+		default:
+			// The status is already sent, aborting the connection is the only way to let the client
+			// know that the response is incomplete.
+			ggreq.Logger.Error("Failed to stream response", slog.String("error", err.Error()))
+			panic(http.ErrAbortHandler)
 		}
+		return
 	}
 
 	w.WriteHeader(statusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if len(responseData) == 0 {
+		return
+	}
+	// The status is already sent, e.g. the client went away, so the failure is only logged.
 	_, err := w.Write(responseData)
 	if err != nil {
-		u.Logger.Warn("Failed to write response", slog.String("error", err.Error()))
+		ggreq.Logger.Warn("Failed to write response", slog.String("error", err.Error()))
 	}
 }
 
+// streamResponseWriter sends the status code with the first write, so a body writer failing
+// before it has written anything can still be answered with an error status.
+type streamResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	writeErr    error
+}
+
+func (w *streamResponseWriter) writeHeader() {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(w.statusCode)
+	}
+}
+
+func (w *streamResponseWriter) Write(b []byte) (int, error) {
+	w.writeHeader()
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil {
+		w.writeErr = err
+	}
+	return n, err
+}
+
+func (w *streamResponseWriter) Flush() {
+	w.writeHeader()
+	if err := http.NewResponseController(w.ResponseWriter).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		w.writeErr = err
+	}
+}
+
+func (w *streamResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withRequestHook calls the hook before the handler.
+func withRequestHook[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), hook func(*GGRequest[TServiceProvider, TReqBody, TGetParams])) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		hook(ggreq)
+		return hFunc(ggreq)
+	}
+}
+
+// withSuccessStatusCode sets the status code of successful handler responses which don't set it.
+// It wraps the handler itself, so middlewares see the final status code.
+func withSuccessStatusCode[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), statusCode int) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		ggresp, err := hFunc(ggreq)
+		if err == nil && ggresp != nil && ggresp.StatusCode == 0 && !ggresp.ErrorOccured {
+			ggresp.StatusCode = statusCode
+		}
+		return ggresp, err
+	}
+}
+
+// resolveResponse returns the status code and the body ServeHTTP writes for the given handler result.
+// A nil response without error means no content.
+func resolveResponse[TRespBody, TErrorData any](ggresp *GGResponse[TRespBody, TErrorData], err error) (int, []byte) {
+	if err != nil {
+		var mProcError MiddlewareProcessingError
+		if errors.As(err, &mProcError) {
+			return mProcError.StatusCode, []byte(mProcError.Message)
+		}
+		return http.StatusInternalServerError, nil
+	}
+	if ggresp == nil {
+		return http.StatusNoContent, nil
+	}
+
+	switch {
+	case ggresp.StatusCode != 0:
+		return ggresp.StatusCode, ggresp.serializedResponse
+	case ggresp.ErrorOccured:
+		return http.StatusInternalServerError, ggresp.serializedResponse
+	default:
+		return http.StatusOK, ggresp.serializedResponse
+	}
+}
+
+// StatusCoder is implemented by errors which define the response status code themselves.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// MatchError finds the first error of type T in the err's tree. Use it in error handlers
+// instead of type switches, so wrapped errors are matched as well:
+//
+//	if dbErr, ok := MatchError[DatabaseError](err); ok {
+//		return http.StatusInternalServerError, &ErrorData{Message: dbErr.Error()}
+//	}
+func MatchError[T error](err error) (T, bool) {
+	var target T
+	ok := errors.As(err, &target)
+	return target, ok
+}
+
+// FallbackErrorHandler builds an error handler matching any error, meant to be the last one
+// passed to GetErrorHandlingMiddleware. The status code is 500, or the one carried by
+// MiddlewareProcessingError.
+func FallbackErrorHandler[TErrorData any](errorDataFunc func(err error, l *slog.Logger) *TErrorData) func(err error, l *slog.Logger) (int, *TErrorData) {
+	return func(err error, l *slog.Logger) (int, *TErrorData) {
+		statusCode := http.StatusInternalServerError
+		var mProcError MiddlewareProcessingError
+		if errors.As(err, &mProcError) {
+			statusCode = mProcError.StatusCode
+		}
+		return statusCode, errorDataFunc(err, l)
+	}
+}
+
+// handleError runs error handlers for the error until one of them returns a non-zero status code.
+// The status code of StatusCoder errors takes precedence over the handler's one.
+func handleError[TErrorData any](err error, l *slog.Logger, errorHandlers []func(err error, l *slog.Logger) (int, *TErrorData)) (statusCode int, errorData *TErrorData, handled bool) {
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		statusCode = statusCoder.StatusCode()
+	}
+	for _, errorHandlerFunc := range errorHandlers {
+		handlerStatusCode, handlerErrorData := errorHandlerFunc(err, l)
+		if handlerStatusCode != 0 {
+			if statusCode == 0 {
+				statusCode = handlerStatusCode
+			}
+			return statusCode, handlerErrorData, true
+		}
+	}
+	if httpError, ok := MatchError[HTTPError](err); ok {
+		if errorData, ok := any(&httpError).(*TErrorData); ok {
+			return statusCode, errorData, true
+		}
+	}
+	return statusCode, nil, false
+}
+
+// GetErrorHandlingMiddleware converts errors returned by the handler into error data.
+// Errors are passed to error handlers as returned by the handler, with wrapping preserved.
+// Error handlers are tried in order, the first one returning a non-zero status code defines
+// the response. If the error implements StatusCoder, its status code takes precedence over
+// the one returned by error handlers, which are then only used to build the error data.
+// HTTPError is rendered as is when TErrorData is HTTPError and no error handler handles it.
 func GetErrorHandlingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](errorHandlers ...func(err error, l *slog.Logger) (int, *TErrorData)) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
 	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
 		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
@@ -117,18 +479,14 @@ func GetErrorHandlingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGet
 			ggresp, err := hFunc(ggreq)
 			if err != nil {
 				ggreq.Logger.Warn("Going to handle error", slog.String("error", err.Error()))
-				statusCode := http.StatusOK // FIXME
-				var errorData *TErrorData
-				for _, errorHandlerFunc := range errorHandlers {
-					statusCode, errorData = errorHandlerFunc(err, ggreq.Logger)
-					if statusCode != 0 {
-						break
-					}
-				}
+				statusCode, errorData, _ := handleError(err, ggreq.Logger, errorHandlers)
 				if statusCode == 0 {
 					return ggresp, err
 				}
 
+				if ggresp == nil {
+					ggresp = &GGResponse[TRespBody, TErrorData]{}
+				}
 				ggresp.ErrorData = errorData
 				ggresp.StatusCode = statusCode
 				ggresp.ErrorOccured = true
@@ -140,61 +498,390 @@ func GetErrorHandlingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGet
 	}
 }
 
+// GetLocalizedErrorHandlingMiddleware is GetErrorHandlingMiddleware translating messages of error data
+// implementing Localizable, see PreferredLanguages. localize gets the message code and the client's
+// languages from Accept-Language. Error data is copied before being localized, so error handlers may
+// return shared values. Decoding and validation errors rendered by DataProcessingMiddleware are not localized.
+func GetLocalizedErrorHandlingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](localize func(code string, langs []language.Tag) string, errorHandlers ...func(err error, l *slog.Logger) (int, *TErrorData)) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	errorHandling := GetErrorHandlingMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody](errorHandlers...)
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		next := errorHandling(hFunc)
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggresp, err := next(ggreq)
+			if ggresp != nil && ggresp.ErrorOccured && ggresp.ErrorData != nil {
+				errorData := *ggresp.ErrorData
+				localizeErrorData(&errorData, ggreq.PreferredLanguages(), localize)
+				ggresp.ErrorData = &errorData
+			}
+			return ggresp, err
+		}
+	}
+}
+
+const defaultMaxMultipartMemory = 32 << 20
+
+// isBodylessMethod reports whether requests with the method are not expected to have a body.
+func isBodylessMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete
+}
+
+// DataProcessingMiddlewareSettings configures GetDataProcessingMiddleware.
+//
+// Besides query parameters, GetParams fields tagged with `path` are filled from the
+// wildcards of the matched ServeMux pattern. For "GET /get_value/{key}":
+//
+//	type GetValueParams struct {
+//		Key string `path:"key" schema:"-"`
+//	}
+//
+// the handler reads ggreq.GetParams.Key instead of calling ggreq.Request.PathValue("key").
+// Fields tagged with `header` are filled from request headers the same way, the ",required"
+// option makes a missing header to be answered with 400:
+//
+//	type GetValueParams struct {
+//		TenantID string `header:"X-Tenant-ID,required" schema:"-"`
+//	}
+//
+// Slice fields of GetParams are filled from repeated keys (?id=1&id=2). Non-string slices
+// also accept comma-separated values (?id=1,2), CommaSeparatedGetParams extends it to
+// string slices.
 type DataProcessingMiddlewareSettings struct {
 	ForbidUnknownKeysInGetParams bool
+	// ForbidUnknownKeysInBody makes the default JSON deserializer answer with 400 on
+	// keys which don't match any field of the request body struct.
+	ForbidUnknownKeysInBody bool
+	// GetParamsTag is the struct tag naming GetParams fields, "schema" by default.
+	// It applies to urlencoded request bodies as well.
+	GetParamsTag string
+	// CommaSeparatedGetParams splits comma-separated values of slice fields in GetParams.
+	CommaSeparatedGetParams bool
+	// TimeLayouts are tried after RFC3339 when decoding time.Time fields of GetParams,
+	// e.g. time.DateOnly.
+	TimeLayouts []string
+	// StreamResponse makes the response body to be encoded directly into the
+	// response writer instead of being buffered. Encoding errors can't change
+	// the status code in this mode, they are only logged. Serializers not
+	// implementing StreamingSerializer keep buffering the body.
+	StreamResponse bool
+	// Serializer is used for both response and error data, JSONSerializer by default.
+	Serializer Serializer
+	// Serializers maps media types to serializers used instead of Serializer when the
+	// Accept header prefers them, e.g. with Deserializers for MessagePack bodies:
+	//
+	//	Serializers:   map[string]Serializer{"application/msgpack": MsgpackSerializer{}},
+	//	Deserializers: map[string]Deserializer{"application/msgpack": MsgpackDeserializer{}},
+	Serializers map[string]Serializer
+	// DisableHTMLEscape and Indent configure the default JSONSerializer, they are
+	// ignored when Serializer is set.
+	DisableHTMLEscape bool
+	Indent            string
+	// Deserializers maps request media types to deserializers. They are added
+	// to the default ones (JSON, urlencoded and multipart forms) and take precedence over
+	// them. Requests without Content-Type are decoded as JSON.
+	Deserializers map[string]Deserializer
+	// RequireContentType makes requests with a body of other media types, or without
+	// Content-Type, to be answered with 415 before decoding. Parameters like charset are ignored.
+	RequireContentType string
+	// MaxMultipartMemory is the part of multipart/form-data bodies kept in memory,
+	// the rest of files is stored in temporary files. 32 MB by default.
+	MaxMultipartMemory int64
+	// MaxBodyBytes limits the size of request bodies, zero means no limit.
+	// For compressed bodies both the compressed and decompressed sizes are limited.
+	MaxBodyBytes int64
+	// Validate enables validation of request data and get params with `validate` struct tags,
+	// see github.com/go-playground/validator for the rules, and with their Validate method,
+	// see Validatable.
+	Validate bool
+	// FormatFieldError formats the message of a failed field for the validation error.
+	FormatFieldError func(validator.FieldError) string
+	// RejectMissingRequiredFields makes the default JSON deserializer answer with 400 when
+	// fields tagged with `json:"name,required"` are absent, see JSONDeserializer.
+	RejectMissingRequiredFields bool
+	// DecodeBodyForAllMethods enables decoding of request bodies for GET, HEAD and DELETE
+	// requests, which are ignored by default. Bodies are never decoded for empty struct TReqBody.
+	DecodeBodyForAllMethods bool
+	// KeepNullResponseBody disables responding with 204 and no body when the handler
+	// returns nil ResponseData without setting StatusCode. A "null" body with 200 is sent instead.
+	KeepNullResponseBody bool
 }
 
-func GetDataProcessingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](settings *DataProcessingMiddlewareSettings) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+// GetDataProcessingMiddleware decodes the request into RequestData and GetParams and serializes
+// the response. Request body decoding errors (DecodeError) and validation errors (ValidationError)
+// are rendered as error data by errorHandlers, the same ones GetErrorHandlingMiddleware gets,
+// and sent as plain text when none of them handles the error.
+func GetDataProcessingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](settings *DataProcessingMiddlewareSettings, errorHandlers ...func(err error, l *slog.Logger) (int, *TErrorData)) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if settings == nil {
+		settings = &DataProcessingMiddlewareSettings{}
+	}
+
+	serializer := settings.Serializer
+	if serializer == nil {
+		serializer = JSONSerializer{DisableHTMLEscape: settings.DisableHTMLEscape, Indent: settings.Indent}
+	}
+
+	var negotiableSerializers map[string]Serializer
+	if len(settings.Serializers) > 0 {
+		negotiableSerializers = map[string]Serializer{serializer.ContentType(): serializer}
+		maps.Copy(negotiableSerializers, settings.Serializers)
+	}
+
+	ndjsonSerializer := JSONSerializer{DisableHTMLEscape: settings.DisableHTMLEscape}
+
+	getParamsDecoder := schema.NewDecoder()
+	getParamsTag := settings.GetParamsTag
+	if getParamsTag == "" {
+		getParamsTag = "schema"
+	}
+	getParamsDecoder.SetAliasTag(getParamsTag)
+	getParamsDecoder.IgnoreUnknownKeys(!settings.ForbidUnknownKeysInGetParams)
+	getParamsDecoder.RegisterConverter(time.Time{}, timeConverter(settings.TimeLayouts))
+	var commaSeparatedKeys map[string]bool
+	if settings.CommaSeparatedGetParams {
+		commaSeparatedKeys = sliceFieldKeys(reflect.TypeFor[TGetParams](), getParamsTag)
+	}
+
+	var validate *validator.Validate
+	if settings.Validate {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+	}
+	formatFieldError := settings.FormatFieldError
+	if formatFieldError == nil {
+		formatFieldError = defaultFieldErrorFormatter
+	}
+
+	maxMultipartMemory := settings.MaxMultipartMemory
+	if maxMultipartMemory == 0 {
+		maxMultipartMemory = defaultMaxMultipartMemory
+	}
+	deserializers := map[string]Deserializer{
+		"application/json": JSONDeserializer{
+			RejectMissingRequired: settings.RejectMissingRequiredFields,
+			DisallowUnknownFields: settings.ForbidUnknownKeysInBody,
+		},
+		"application/x-www-form-urlencoded": FormDeserializer{Decoder: getParamsDecoder},
+		"multipart/form-data":               MultipartDeserializer{Decoder: getParamsDecoder, MaxMemory: maxMultipartMemory},
+	}
+	for mediaType, deserializer := range settings.Deserializers {
+		deserializers[mediaType] = deserializer
+	}
+
+	reqBodyType := reflect.TypeFor[TReqBody]()
+	emptyReqBody := reqBodyType.Kind() == reflect.Struct && reqBodyType.NumField() == 0
+
+	// decodeRequest fills RequestData and GetParams. Body decoding and validation errors are
+	// returned as DecodeError and ValidationError to be rendered by error handlers.
+	decodeRequest := func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) error {
+		var reqBody TReqBody
+		decodeBody := !emptyReqBody && (settings.DecodeBodyForAllMethods || !isBodylessMethod(ggreq.Request.Method))
+		if decodeBody && ggreq.Request.Body != http.NoBody && ggreq.Request.Body != nil {
+			mediaType := "application/json"
+			contentType := ggreq.Request.Header.Get("Content-Type")
+			if contentType != "" {
+				parsedMediaType, _, err := mime.ParseMediaType(contentType)
+				if err != nil {
+					return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+				}
+				mediaType = parsedMediaType
+			}
+			if settings.RequireContentType != "" && (contentType == "" || !strings.EqualFold(mediaType, settings.RequireContentType)) {
+				return MiddlewareProcessingError{
+					Message:    fmt.Sprintf("content type %q is required", settings.RequireContentType),
+					StatusCode: http.StatusUnsupportedMediaType,
+				}
+			}
+			deserializer, ok := deserializers[mediaType]
+			if !ok {
+				return MiddlewareProcessingError{
+					Message:    fmt.Sprintf("unsupported content type %q", mediaType),
+					StatusCode: http.StatusUnsupportedMediaType,
+				}
+			}
+			if settings.MaxBodyBytes > 0 {
+				ggreq.Request.Body = http.MaxBytesReader(nil, ggreq.Request.Body, settings.MaxBodyBytes)
+			}
+			decompressed, err := decompressRequestBody(ggreq.Request)
+			if err != nil {
+				return err
+			}
+			if decompressed && settings.MaxBodyBytes > 0 {
+				ggreq.Request.Body = http.MaxBytesReader(nil, ggreq.Request.Body, settings.MaxBodyBytes)
+			}
+			err = deserializer.Deserialize(ggreq.Request, &reqBody)
+			if err != nil {
+				slog.Info(
+					"Error decoding request body",
+					"error", err,
+				)
+				var maxBytesError *http.MaxBytesError
+				if errors.As(err, &maxBytesError) || errors.Is(err, multipart.ErrMessageTooLarge) {
+					return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusRequestEntityTooLarge}
+				}
+				return newDecodeError(err)
+			}
+		}
+		ggreq.RequestData = &reqBody
+		ggreq.MultipartForm = ggreq.Request.MultipartForm
+
+		var getParams TGetParams
+		query := ggreq.Request.URL.Query()
+		if commaSeparatedKeys != nil {
+			query = splitCommaSeparated(query, commaSeparatedKeys)
+		}
+		err := getParamsDecoder.Decode(&getParams, query)
+		if err != nil {
+			return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		err = bindTaggedValues(&getParams, "path", func(name string) (string, bool) {
+			value := ggreq.Request.PathValue(name)
+			return value, value != ""
+		})
+		if err != nil {
+			return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		err = bindTaggedValues(&getParams, "header", func(name string) (string, bool) {
+			values := ggreq.Request.Header.Values(name)
+			if len(values) == 0 {
+				return "", false
+			}
+			return values[0], true
+		})
+		if err != nil {
+			return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		ggreq.GetParams = &getParams
+
+		if validate != nil {
+			return validateAll(validate, formatFieldError, ggreq.RequestData, ggreq.GetParams)
+		}
+		return nil
+	}
+
+	// renderRequestError builds an error response for DecodeError and ValidationError with
+	// error handlers, other errors and errors not handled by them are sent as plain text.
+	renderRequestError := func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams], err error) (*GGResponse[TRespBody, TErrorData], error) {
+		var mProcError MiddlewareProcessingError
+		if errors.As(err, &mProcError) {
+			return &GGResponse[TRespBody, TErrorData]{}, err
+		}
+		statusCode, errorData, handled := handleError(err, ggreq.Logger, errorHandlers)
+		if !handled {
+			return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{Message: err.Error(), StatusCode: statusCode}
+		}
+		return &GGResponse[TRespBody, TErrorData]{ErrorOccured: true, ErrorData: errorData, StatusCode: statusCode}, nil
+	}
+
 	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
 		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
 			ggreq.Logger.Debug("DataProcessingMiddleware start")
-			if settings == nil {
-				settings = &DataProcessingMiddlewareSettings{}
+
+			var ggresp *GGResponse[TRespBody, TErrorData]
+			var err error
+			requestErr := decodeRequest(ggreq)
+			// Temporary files of the form are removed whether the request is handled or rejected.
+			if form := ggreq.Request.MultipartForm; form != nil {
+				defer form.RemoveAll()
+			}
+			if requestErr != nil {
+				ggresp, err = renderRequestError(ggreq, requestErr)
+			} else {
+				ggresp, err = hFunc(ggreq)
+			}
+			if err != nil {
+				return &GGResponse[TRespBody, TErrorData]{}, err
+			}
+			if ggreq.responseTakenOver {
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, response taken over")
+				return &GGResponse[TRespBody, TErrorData]{}, nil
+			}
+			if ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
 			}
 
-			var reqBody TReqBody
-			if ggreq.Request.Body != http.NoBody && ggreq.Request.Body != nil {
-				err := json.NewDecoder(ggreq.Request.Body).Decode(&reqBody)
-				if err != nil {
-					slog.Info(
-						"Error decoding request body",
-						"error", err,
-					)
-					return nil, MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+			if ggresp.Body != nil && !ggresp.ErrorOccured {
+				body := ggresp.Body
+				ggresp.bodyWriter = func(w io.Writer) error {
+					if closer, ok := body.(io.Closer); ok {
+						defer closer.Close()
+					}
+					_, err := io.Copy(w, body)
+					return err
 				}
+				contentType := ggresp.ContentType
+				if contentType == "" {
+					contentType = "application/octet-stream"
+				}
+				ggresp.SetHeader("Content-Type", contentType)
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, raw body")
+				return ggresp, nil
 			}
-			ggreq.RequestData = &reqBody
 
-			getParamsDecoder := schema.NewDecoder()
-			getParamsDecoder.IgnoreUnknownKeys(!settings.ForbidUnknownKeysInGetParams)
-			var getParams TGetParams
-			err := getParamsDecoder.Decode(&getParams, ggreq.Request.URL.Query())
-			if err != nil {
-				return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+			if ggresp.RawBody != nil && !ggresp.ErrorOccured {
+				ggresp.serializedResponse = ggresp.RawBody
+				contentType := ggresp.ContentType
+				if contentType == "" {
+					contentType = serializer.ContentType()
+				}
+				ggresp.SetHeader("Content-Type", contentType)
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, raw body")
+				return ggresp, nil
 			}
-			ggreq.GetParams = &getParams
 
-			ggresp, err := hFunc(ggreq)
-			if err != nil {
-				return &GGResponse[TRespBody, TErrorData]{}, err
+			if ggresp.Stream != nil && !ggresp.ErrorOccured {
+				ggresp.bodyWriter = func(w io.Writer) error {
+					return writeNDJSON(w, ggresp.Stream, ndjsonSerializer)
+				}
+				ggresp.SetHeader("Content-Type", "application/x-ndjson")
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, stream")
+				return ggresp, nil
+			}
+
+			if ggresp.isBodylessRedirect() {
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, redirect")
+				return ggresp, nil
 			}
 
-			var bodySerialized []byte
-			var serializationError error
+			if !settings.KeepNullResponseBody && !ggresp.ErrorOccured && ggresp.ResponseData == nil && ggresp.StatusCode == 0 {
+				ggresp.StatusCode = http.StatusNoContent
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, no content")
+				return ggresp, nil
+			}
+			if ggresp.StatusCode == http.StatusNoContent {
+				ggreq.Logger.Debug("DataProcessingMiddleware finish, no content")
+				return ggresp, nil
+			}
 
+			var bodyData any
 			if !ggresp.ErrorOccured {
-				bodySerialized, serializationError = json.Marshal(ggresp.ResponseData)
+				bodyData = ggresp.ResponseData
 			} else {
-				bodySerialized, serializationError = json.Marshal(ggresp.ErrorData)
+				bodyData = ggresp.ErrorData
 			}
-			if serializationError != nil {
-				return ggresp, MiddlewareProcessingError{Message: serializationError.Error(), StatusCode: http.StatusBadRequest}
+
+			serializer := serializer
+			if negotiableSerializers != nil {
+				if negotiated, ok := negotiateSerializer(ggreq.Request.Header.Get("Accept"), negotiableSerializers); ok {
+					serializer = negotiated
+				}
+				ggresp.AddHeader("Vary", "Accept")
 			}
-			ggresp.serializedResponse = bodySerialized
-			if ggresp.Headers == nil {
-				ggresp.Headers = make(map[string][]string)
+			if streamingSerializer, ok := serializer.(StreamingSerializer); ok && settings.StreamResponse {
+				ggresp.bodyWriter = func(w io.Writer) error {
+					return streamingSerializer.Encode(w, bodyData)
+				}
+			} else {
+				bodySerialized, serializationError := serializer.Marshal(bodyData)
+				if serializationError != nil {
+					// The response can't be serialized, it's a server fault.
+					ggreq.Logger.Error(
+						"Failed to serialize response",
+						slog.String("type", fmt.Sprintf("%T", bodyData)),
+						slog.String("error", serializationError.Error()),
+					)
+					return ggresp, MiddlewareProcessingError{Message: http.StatusText(http.StatusInternalServerError), StatusCode: http.StatusInternalServerError}
+				}
+				ggresp.serializedResponse = bodySerialized
 			}
-			ggresp.Headers["content-type"] = []string{"application/json"}
+			ggresp.SetHeader("Content-Type", serializer.ContentType())
 
 			ggreq.Logger.Debug("DataProcessingMiddleware finish")
 			return ggresp, err
@@ -202,56 +889,156 @@ func GetDataProcessingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGe
 	}
 }
 
+// NewUUID returns a random (version 4) UUID, the default request ID. It's implemented with
+// crypto/rand, so no UUID library is needed for request IDs. It panics if the random source
+// fails, like uuid.New does.
+func NewUUID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(fmt.Sprintf("gogohandlers: generating UUID: %v", err))
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+type RequestIDConfig struct {
+	// HeaderName is the request header the ID is taken from and the response header it is echoed in,
+	// X-Request-Id by default.
+	HeaderName string
+	// Generator creates IDs for requests which don't have one, NewUUID by default. Set it to use
+	// other IDs, like ULIDs or snowflakes.
+	Generator func() string
+	// OmitFromResponse disables echoing the ID in the response headers.
+	OmitFromResponse bool
+}
+
+// GetRequestIDMiddleware puts the request ID into the request context and echoes it in the response
+// headers. When the handler panics, the header is set on the response writer directly, so responses
+// written by an outer RecoveryMiddleware carry it as well.
+func GetRequestIDMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg RequestIDConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-Request-Id"
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = NewUUID
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("RequestIDMiddleware start")
+			requestID := ggreq.Request.Header.Get(cfg.HeaderName)
+			if requestID == "" {
+				requestID = cfg.Generator()
+			}
+			ggreq.Request = ggreq.Request.WithContext(context.WithValue(ggreq.Request.Context(), requestIDContextKey, requestID))
+			returned := false
+			defer func() {
+				if !returned && !cfg.OmitFromResponse && ggreq.responseWriter != nil {
+					ggreq.responseWriter.Header().Set(cfg.HeaderName, requestID)
+				}
+			}()
+			ggresp, err := hFunc(ggreq)
+			returned = true
+			if ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+			}
+
+			if !cfg.OmitFromResponse {
+				ggresp.SetHeader(cfg.HeaderName, requestID)
+			}
+			ggreq.Logger.Debug("RequestIDMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
+
 // func RequestIDMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody any](hFunc THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody]) THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody] {
 func RequestIDMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
-	return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
-		ggreq.Logger.Debug("RequestIDMiddleware start")
-		var requestID string
-		if requestIDHeader, ok := ggreq.Request.Header["X-Request-Id"]; ok {
-			requestID = requestIDHeader[0]
-		} else {
-			requestID = uuid.New().String()
-		}
-		ggreq.Request = ggreq.Request.WithContext(context.WithValue(ggreq.Request.Context(), requestIDContextKey, requestID))
-		ggresp, err := hFunc(ggreq)
+	return GetRequestIDMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](RequestIDConfig{})(hFunc)
+}
+
+type LoggingConfig struct {
+	// SlowThreshold makes requests taking longer to be logged at Warn level with slow=true.
+	// Zero disables it.
+	SlowThreshold time.Duration
+	// LogRequestBody and LogResponseBody add bodies to the log lines, at most MaxLoggedBodyBytes
+	// (4096 by default) of them. Values of JSON keys listed in RedactFields are replaced with "***",
+	// bodies which can't be redacted are omitted.
+	LogRequestBody     bool
+	LogResponseBody    bool
+	RedactFields       []string
+	MaxLoggedBodyBytes int
+}
+
+func GetRequestLoggingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg LoggingConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if cfg.MaxLoggedBodyBytes <= 0 {
+		cfg.MaxLoggedBodyBytes = defaultMaxLoggedBodyBytes
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("RequestLoggingMiddleware start")
+			requestID, _ := RequestIDFromContext(ggreq.Request.Context())
+			ggreq.Logger = ggreq.Logger.With(
+				slog.String("request_id", requestID),
+			)
+
+			newRequestAttrs := []slog.Attr{
+				slog.String("method", ggreq.Request.Method),
+				slog.String("url", ggreq.Request.URL.String()),
+			}
+			if cfg.LogRequestBody {
+				requestBody, err := peekRequestBody(ggreq.Request, cfg.MaxLoggedBodyBytes)
+				if err != nil {
+					ggreq.Logger.Warn("Failed to read request body for logging", slog.String("error", err.Error()))
+				}
+				newRequestAttrs = append(newRequestAttrs, slog.String("request_body", formatLoggedBody(requestBody, cfg.MaxLoggedBodyBytes, cfg.RedactFields)))
+			}
+			ggreq.Logger.LogAttrs(ggreq.Request.Context(), slog.LevelInfo, "New request", newRequestAttrs...)
+			start := time.Now()
+			ggresp, err := hFunc(ggreq)
+			elapsed := time.Since(start)
+			statusCode, responseData := resolveResponse(ggresp, err)
 
-		if ggresp.Headers == nil {
-			ggresp.Headers = make(map[string][]string)
+			level := slog.LevelInfo
+			attrs := []slog.Attr{
+				slog.String("method", ggreq.Request.Method),
+				slog.String("url", ggreq.Request.URL.String()),
+				slog.Duration("duration", elapsed),
+			}
+			if ggreq.responseTakenOver {
+				// The status is unknown, it was written by the handler itself.
+				attrs = append(attrs, slog.Bool("taken_over", true))
+			} else {
+				attrs = append(attrs, slog.Int("status", statusCode), slog.Int("bytes", len(responseData)))
+			}
+			if cfg.LogResponseBody && !ggreq.responseTakenOver {
+				attrs = append(attrs, slog.String("response_body", formatLoggedBody(responseData, cfg.MaxLoggedBodyBytes, cfg.RedactFields)))
+			}
+			if cfg.SlowThreshold > 0 && elapsed > cfg.SlowThreshold {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Bool("slow", true))
+			}
+			ggreq.Logger.LogAttrs(ggreq.Request.Context(), level, "Request finished", attrs...)
+			ggreq.Logger.Debug("RequestLoggingMiddleware finish")
+			return ggresp, err
 		}
-		ggresp.Headers["X-Request-Id"] = []string{requestID}
-		ggreq.Logger.Debug("RequestIDMiddleware finish")
-		return ggresp, err
 	}
 }
 
 // func RequestLoggingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody any](hFunc THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody]) THandlerFunc[TServiceProvider, TReqBody, TGetParams, TRespBody] {
 func RequestLoggingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
-	return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
-		ggreq.Logger.Debug("RequestLoggingMiddleware start")
-		reqIDValue := ggreq.Request.Context().Value(requestIDContextKey)
-		var requestID string
-		if reqIDValue != nil {
-			requestID = reqIDValue.(string)
-		}
-		ggreq.Logger = ggreq.Logger.With(
-			slog.String("request_id", requestID),
-		)
-
-		ggreq.Logger.Info(
-			"New request",
-			slog.String("method", ggreq.Request.Method),
-			slog.String("url", ggreq.Request.URL.String()),
-		)
-		start := time.Now()
-		ggresp, err := hFunc(ggreq)
-		elapsed := time.Since(start)
-		ggreq.Logger.Info(
-			"Request finished",
-			slog.String("method", ggreq.Request.Method),
-			slog.String("url", ggreq.Request.URL.String()),
-			slog.Duration("duration", elapsed),
-		)
-		ggreq.Logger.Debug("RequestLoggingMiddleware finish")
-		return ggresp, err
-	}
+	return GetRequestLoggingMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](LoggingConfig{})(hFunc)
 }
@@ -0,0 +1,70 @@
+package gogohandlers_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dmi-feo/gogohandlers"
+	"github.com/dmi-feo/gogohandlers/ggtest"
+)
+
+type serviceProvider struct{}
+
+type getParams struct{}
+
+type response struct {
+	Value string `json:"value"`
+}
+
+type errorData struct {
+	Message string `json:"message"`
+}
+
+func TestServeHTTPWithNilLogger(t *testing.T) {
+	u := gogohandlers.NewHandler(&serviceProvider{}, func(*gogohandlers.GGRequest[serviceProvider, struct{}, getParams]) (*gogohandlers.GGResponse[response, errorData], error) {
+		return &gogohandlers.GGResponse[response, errorData]{ResponseData: &response{Value: "ok"}}, nil
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	u.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	ggtest.AssertStatus(t, rec, http.StatusOK)
+	ggtest.AssertJSONBody(t, rec, &response{Value: "ok"})
+}
+
+type databaseError struct {
+	Query string
+}
+
+func (e databaseError) Error() string {
+	return "database error in " + e.Query
+}
+
+func TestErrorHandlingMiddlewareMatchesWrappedErrors(t *testing.T) {
+	var handledErr error
+	errorHandler := func(err error, l *slog.Logger) (int, *errorData) {
+		dbErr, ok := gogohandlers.MatchError[databaseError](err)
+		if !ok {
+			return 0, nil
+		}
+		handledErr = err
+		return http.StatusServiceUnavailable, &errorData{Message: dbErr.Query}
+	}
+	u := gogohandlers.NewHandler(&serviceProvider{}, func(*gogohandlers.GGRequest[serviceProvider, struct{}, getParams]) (*gogohandlers.GGResponse[response, errorData], error) {
+		return nil, fmt.Errorf("query failed: %w", databaseError{Query: "SELECT 1"})
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	u.Middlewares = gogohandlers.DefaultMiddlewares[serviceProvider, struct{}, getParams, response](nil, errorHandler)
+
+	rec := httptest.NewRecorder()
+	u.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	ggtest.AssertStatus(t, rec, http.StatusServiceUnavailable)
+	ggtest.AssertErrorData(t, rec, &errorData{Message: "SELECT 1"})
+	if handledErr == nil || handledErr.Error() != "query failed: database error in SELECT 1" {
+		t.Errorf("error handler got %v, want the wrapping error", handledErr)
+	}
+}
@@ -0,0 +1,565 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/schema"
+)
+
+func TestServeHTTPWritesMultiValueHeaders(t *testing.T) {
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{
+			ResponseData: &testResponse{},
+			Headers:      map[string][]string{"Set-Cookie": {"session=1", "csrf=2"}},
+		}, nil
+	})
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Values("Set-Cookie"); !slices.Equal(got, []string{"session=1", "csrf=2"}) {
+		t.Errorf("Set-Cookie = %q, want both cookies", got)
+	}
+}
+
+type tenantGetParams struct {
+	TenantID string `header:"X-Tenant-ID,required" schema:"-"`
+}
+
+func newMultipartRequest(t *testing.T, fieldValue string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("key", fieldValue); err != nil {
+		t.Fatal(err)
+	}
+	if file != nil {
+		fw, err := mw.CreateFormFile("upload", "upload.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMultipartTempFilesRemovedWhenRequestIsRejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	called := false
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, testRequest, tenantGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		called = true
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, tenantGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{MaxMultipartMemory: 1})
+
+	rec := serve(t, u, newMultipartRequest(t, "v", bytes.Repeat([]byte("x"), 1024)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Error("handler was called without the required header")
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("temporary files left: %d", len(entries))
+	}
+}
+
+func TestMultipartMessageTooLargeIs413(t *testing.T) {
+	u := newTestHandler(okHandler("ok"))
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{MaxMultipartMemory: 1})
+
+	// Non-file values may take MaxMultipartMemory plus 10 MB.
+	rec := serve(t, u, newMultipartRequest(t, strings.Repeat("x", 10<<20+1), nil))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+type pingGetParams struct {
+	Name  string `schema:"name"`
+	Count int    `schema:"count"`
+}
+
+// BenchmarkGetParamsDecoding decodes the same get params 100k times per iteration with the decoder
+// DataProcessingMiddleware builds once and, for comparison, with a decoder built per request.
+func BenchmarkGetParamsDecoding(b *testing.B) {
+	query := url.Values{"name": {"ping"}, "count": {"3"}}
+	newDecoder := func() *schema.Decoder {
+		decoder := schema.NewDecoder()
+		decoder.IgnoreUnknownKeys(true)
+		return decoder
+	}
+	decode := func(b *testing.B, decoderFor func() *schema.Decoder) {
+		b.ReportAllocs()
+		for range b.N {
+			for range 100_000 {
+				var params pingGetParams
+				if err := decoderFor().Decode(&params, query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		decoder := newDecoder()
+		decode(b, func() *schema.Decoder { return decoder })
+	})
+	b.Run("per request", func(b *testing.B) {
+		decode(b, newDecoder)
+	})
+	b.Run("middleware", func(b *testing.B) {
+		hFunc := GetDataProcessingMiddleware[testServiceProvider, testRequest, pingGetParams, testResponse, testErrorData](nil)(
+			func(*GGRequest[testServiceProvider, testRequest, pingGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return nil, nil
+			},
+		)
+		request := httptest.NewRequest(http.MethodGet, "/ping?"+query.Encode(), nil)
+		logger := discardLogger()
+		b.ReportAllocs()
+		for range b.N {
+			for range 100_000 {
+				ggreq := &GGRequest[testServiceProvider, testRequest, pingGetParams]{Request: request, Logger: logger}
+				if _, err := hFunc(ggreq); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func TestSetCookie(t *testing.T) {
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		ggresp := &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}
+		ggresp.SetCookie(&http.Cookie{Name: "session", Value: "s1", HttpOnly: true})
+		ggresp.SetCookie(&http.Cookie{Name: "csrf", Value: "c1"})
+		ggresp.SetCookie(&http.Cookie{Name: "invalid name", Value: "skipped"})
+		return ggresp, nil
+	})
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{}`)))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("cookies = %v, want session and csrf", cookies)
+	}
+	if cookies[0].Name != "session" || cookies[0].Value != "s1" || !cookies[0].HttpOnly {
+		t.Errorf("first cookie = %v", cookies[0])
+	}
+	if cookies[1].Name != "csrf" || cookies[1].Value != "c1" {
+		t.Errorf("second cookie = %v", cookies[1])
+	}
+}
+
+func TestServeHTTPSetsContentLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		resp   *GGResponse[testResponse, testErrorData]
+		length string
+	}{
+		{name: "body", resp: &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: "ok"}}, length: "14"},
+		{name: "no content", resp: nil, length: ""},
+		{name: "not modified", resp: &GGResponse[testResponse, testErrorData]{StatusCode: http.StatusNotModified}, length: ""},
+		{name: "stream", resp: &GGResponse[testResponse, testErrorData]{Body: strings.NewReader("raw")}, length: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return tt.resp, nil
+			})
+
+			rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if got := rec.Header().Get("Content-Length"); got != tt.length {
+				t.Errorf("Content-Length = %q, want %q", got, tt.length)
+			}
+			if tt.length != "" && tt.length != strconv.Itoa(rec.Body.Len()) {
+				t.Errorf("Content-Length = %s, body length = %d", tt.length, rec.Body.Len())
+			}
+		})
+	}
+}
+
+type sliceGetParams struct {
+	IDs  []int    `schema:"id"`
+	Tags []string `schema:"tag"`
+}
+
+func TestGetParamsSlices(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		commaSeparated bool
+		wantIDs        []int
+		wantTags       []string
+	}{
+		{name: "repeated keys", query: "id=1&id=2&tag=a&tag=b", wantIDs: []int{1, 2}, wantTags: []string{"a", "b"}},
+		{name: "comma-separated numbers", query: "id=1,2,3&tag=a,b", wantIDs: []int{1, 2, 3}, wantTags: []string{"a,b"}},
+		{name: "comma-separated strings", query: "id=1&id=2,3&tag=a,b", commaSeparated: true, wantIDs: []int{1, 2, 3}, wantTags: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *sliceGetParams
+			u := NewHandler(&testServiceProvider{}, func(ggreq *GGRequest[testServiceProvider, testRequest, sliceGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				got = ggreq.GetParams
+				return nil, nil
+			}, discardLogger())
+			u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, sliceGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{CommaSeparatedGetParams: tt.commaSeparated})
+
+			rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("status = %d %q", rec.Code, rec.Body.String())
+			}
+			if !slices.Equal(got.IDs, tt.wantIDs) || !slices.Equal(got.Tags, tt.wantTags) {
+				t.Errorf("params = %+v, want IDs %v and tags %q", *got, tt.wantIDs, tt.wantTags)
+			}
+		})
+	}
+}
+
+type queryTagGetParams struct {
+	Key   string `query:"key"`
+	Limit int    `query:"limit"`
+}
+
+func TestGetParamsTag(t *testing.T) {
+	var got *queryTagGetParams
+	u := NewHandler(&testServiceProvider{}, func(ggreq *GGRequest[testServiceProvider, testRequest, queryTagGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		got = ggreq.GetParams
+		return nil, nil
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, queryTagGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{GetParamsTag: "query"})
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/?key=k&limit=5", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d %q", rec.Code, rec.Body.String())
+	}
+	if got.Key != "k" || got.Limit != 5 {
+		t.Errorf("params = %+v, want key k and limit 5", *got)
+	}
+}
+
+func TestBodyIgnoredForBodylessMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		settings *DataProcessingMiddlewareSettings
+		want     int
+	}{
+		{name: "GET", method: http.MethodGet, want: http.StatusOK},
+		{name: "DELETE", method: http.MethodDelete, want: http.StatusOK},
+		{name: "POST", method: http.MethodPost, want: http.StatusBadRequest},
+		{name: "GET decoding all methods", method: http.MethodGet, settings: &DataProcessingMiddlewareSettings{DecodeBodyForAllMethods: true}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(okHandler("ok"))
+			u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](tt.settings)
+
+			rec := serve(t, u, httptest.NewRequest(tt.method, "/", strings.NewReader("junk")))
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyIgnoredForEmptyStructRequest(t *testing.T) {
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, struct{}, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	}, discardLogger())
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("junk")))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestDisableHTMLEscapeSetting(t *testing.T) {
+	u := newTestHandler(okHandler("a&b"))
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{DisableHTMLEscape: true})
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Body.String(); got != `{"value":"a&b"}` {
+		t.Errorf("body = %s, want & unescaped", got)
+	}
+}
+
+func TestCreatedWithLocation(t *testing.T) {
+	u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		ggresp := &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: ggreq.RequestData.Value}}
+		ggresp.SetLocation("/get_value/" + ggreq.RequestData.Key)
+		return ggresp, nil
+	})
+	u.SuccessStatusCode = http.StatusCreated
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/set_value", strings.NewReader(`{"key":"k","value":"v"}`)))
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("Location"); got != "/get_value/k" {
+		t.Errorf("Location = %q, want /get_value/k", got)
+	}
+}
+
+type failingResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *failingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *failingResponseWriter) WriteHeader(statusCode int) {
+	w.status = statusCode
+}
+
+func (w *failingResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestServeHTTPLogsWriteFailures(t *testing.T) {
+	for _, streamed := range []bool{false, true} {
+		var logs bytes.Buffer
+		u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+			if streamed {
+				return &GGResponse[testResponse, testErrorData]{Body: strings.NewReader("raw")}, nil
+			}
+			return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+		})
+		u.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		w := &failingResponseWriter{header: make(http.Header)}
+
+		u.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.status != http.StatusOK {
+			t.Errorf("streamed=%v: status = %d, want %d", streamed, w.status, http.StatusOK)
+		}
+		if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "connection reset by peer") {
+			t.Errorf("streamed=%v: logs = %q, want the write failure", streamed, logs.String())
+		}
+	}
+}
+
+type channelResponse struct {
+	Updates chan string `json:"updates"`
+}
+
+func TestUnserializableResponseIs500(t *testing.T) {
+	var logs bytes.Buffer
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[channelResponse, testErrorData], error) {
+		return &GGResponse[channelResponse, testErrorData]{ResponseData: &channelResponse{Updates: make(chan string)}}, nil
+	}, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(logs.String(), "type=*gogohandlers.channelResponse") {
+		t.Errorf("logs = %q, want the response type", logs.String())
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	newBody := func(size int) string {
+		const envelope = `{"key":"k","value":""}`
+		return `{"key":"k","value":"` + strings.Repeat("x", size-len(envelope)) + `"}`
+	}
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "under the limit", body: newBody(50), wantStatus: http.StatusOK},
+		{name: "at the limit", body: newBody(64), wantStatus: http.StatusOK},
+		{name: "over the limit", body: newBody(65), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "far over the limit", body: newBody(10000), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				called = true
+				return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+			})
+			u.Middlewares[1] = GetDataProcessingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{MaxBodyBytes: 64})
+
+			rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "required type", method: http.MethodPost, contentType: "application/json", body: `{"value":"v"}`, wantStatus: http.StatusOK},
+		{name: "required type with parameters", method: http.MethodPost, contentType: "Application/JSON; charset=utf-8", body: `{"value":"v"}`, wantStatus: http.StatusOK},
+		{name: "bodyless method", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "other type", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", body: "value=v", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "no content type", method: http.MethodPost, body: `{"value":"v"}`, wantStatus: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(okHandler("ok"))
+			u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{RequireContentType: "application/json"})
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, "/", body)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			rec := serve(t, u, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestServeHTTPClosesBodyNotSent(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		errorOccured bool
+	}{
+		{name: "GET", method: http.MethodGet},
+		{name: "HEAD", method: http.MethodHead},
+		{name: "error response", method: http.MethodGet, errorOccured: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader("payload")}
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return &GGResponse[testResponse, testErrorData]{Body: body, ErrorOccured: tt.errorOccured, ErrorData: &testErrorData{}}, nil
+			})
+
+			serve(t, u, httptest.NewRequest(tt.method, "/", nil))
+
+			if !body.closed {
+				t.Error("body was not closed")
+			}
+		})
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for range 100 {
+		id := NewUUID()
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("NewUUID() = %q, not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewUUID() = %q repeated", id)
+		}
+		seen[id] = true
+	}
+}
+
+// slowStorage stands for a storage whose queries honor the context, like QueryContext does.
+type slowStorage struct {
+	started chan struct{}
+}
+
+func (s *slowStorage) Get(ctx context.Context, key string) (string, error) {
+	close(s.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestGGRequestContextCancelsServiceProviderCalls(t *testing.T) {
+	storage := &slowStorage{started: make(chan struct{})}
+	queryErr := make(chan error, 1)
+	u := NewHandler(storage, func(ggreq *GGRequest[slowStorage, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		value, err := ggreq.ServiceProvider.Get(ggreq.Context(), "key")
+		queryErr <- err
+		if err != nil {
+			return nil, err
+		}
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: value}}, nil
+	}, discardLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	}()
+	<-storage.started
+	cancel()
+	defer func() { <-served }()
+
+	select {
+	case err := <-queryErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("query error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query was not cancelled")
+	}
+}
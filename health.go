@@ -0,0 +1,61 @@
+package gogohandlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+type HealthCheck func(ctx context.Context) error
+
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+// LivenessHandler always reports the service as alive.
+func LivenessHandler[TServiceProvider ServiceProvider, TErrorData any]() func(*GGRequest[TServiceProvider, struct{}, struct{}]) (*GGResponse[HealthReport, TErrorData], error) {
+	return func(ggreq *GGRequest[TServiceProvider, struct{}, struct{}]) (*GGResponse[HealthReport, TErrorData], error) {
+		return &GGResponse[HealthReport, TErrorData]{ResponseData: &HealthReport{Status: healthStatusOK}}, nil
+	}
+}
+
+// ReadinessHandler runs the checks concurrently and reports the status of each one.
+// The response status is 503 when any check fails, the report is sent in both cases.
+func ReadinessHandler[TServiceProvider ServiceProvider, TErrorData any](checks map[string]HealthCheck) func(*GGRequest[TServiceProvider, struct{}, struct{}]) (*GGResponse[HealthReport, TErrorData], error) {
+	return func(ggreq *GGRequest[TServiceProvider, struct{}, struct{}]) (*GGResponse[HealthReport, TErrorData], error) {
+		report := &HealthReport{Status: healthStatusOK, Checks: make(map[string]string, len(checks))}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := check(ggreq.Request.Context())
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					ggreq.Logger.Warn("Health check failed", slog.String("check", name), slog.String("error", err.Error()))
+					report.Checks[name] = healthStatusFail + ": " + err.Error()
+					report.Status = healthStatusFail
+				} else {
+					report.Checks[name] = healthStatusOK
+				}
+			}()
+		}
+		wg.Wait()
+
+		ggresp := &GGResponse[HealthReport, TErrorData]{ResponseData: report}
+		if report.Status != healthStatusOK {
+			ggresp.StatusCode = http.StatusServiceUnavailable
+		}
+		return ggresp, nil
+	}
+}
@@ -0,0 +1,60 @@
+package gogohandlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testServiceProvider struct{}
+
+type testRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type testGetParams struct{}
+
+type testResponse struct {
+	Value string `json:"value"`
+}
+
+type testErrorData struct {
+	Message string `json:"message"`
+}
+
+type testMiddleware = func(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error)) func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestHandler builds a handler with the standard middleware chain and the given outer middlewares.
+func newTestHandler(hFunc func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error), middlewares ...testMiddleware) *Uitzicht[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData] {
+	return &Uitzicht[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]{
+		ServiceProvider: &testServiceProvider{},
+		HandlerFunc:     hFunc,
+		Middlewares: append([]testMiddleware{
+			GetErrorHandlingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](),
+			GetDataProcessingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](nil),
+			RequestLoggingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData],
+			RequestIDMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData],
+		}, middlewares...),
+		Logger: discardLogger(),
+	}
+}
+
+func serve(t *testing.T, h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func okHandler(value string) func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+	return func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: value}}, nil
+	}
+}
@@ -0,0 +1,53 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// HTTPError is an error carrying the response status and the data to render. Return it as a value:
+//
+//	return nil, gogohandlers.HTTPError{Status: http.StatusNotFound, Code: "not_found", Message: "no such key"}
+//
+// Error handling renders it as error data as is when TErrorData is HTTPError and no error handler
+// handles it. For other error data types use HTTPErrorHandler.
+type HTTPError struct {
+	Status  int               `json:"-" xml:"-" yaml:"-"`
+	Code    string            `json:"code,omitempty" xml:"code,omitempty" yaml:"code,omitempty"`
+	Message string            `json:"message" xml:"message" yaml:"message"`
+	Details map[string]string `json:"details,omitempty" xml:"-" yaml:"details,omitempty"`
+}
+
+func (e HTTPError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.StatusCode())
+	}
+	return e.Message
+}
+
+// StatusCode returns Status, 500 if it's not set.
+func (e HTTPError) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+// Localize translates the message by the error code, so HTTPError used as error data is localized.
+func (e *HTTPError) Localize(translate func(code string) string) {
+	if message := translate(e.Code); message != "" {
+		e.Message = message
+	}
+}
+
+// HTTPErrorHandler builds an error handler for GetErrorHandlingMiddleware matching HTTPError
+// and mapping it to the error data with errorDataFunc.
+func HTTPErrorHandler[TErrorData any](errorDataFunc func(httpError HTTPError) *TErrorData) func(err error, l *slog.Logger) (int, *TErrorData) {
+	return func(err error, l *slog.Logger) (int, *TErrorData) {
+		httpError, ok := MatchError[HTTPError](err)
+		if !ok {
+			return 0, nil
+		}
+		return httpError.StatusCode(), errorDataFunc(httpError)
+	}
+}
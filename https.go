@@ -0,0 +1,63 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+type HTTPSConfig struct {
+	// TrustedProxies are IPs or CIDR prefixes of proxies whose X-Forwarded-Proto is taken into account.
+	TrustedProxies []string
+	// Redirect makes plain HTTP requests to be redirected to HTTPS instead of being rejected with 403.
+	Redirect bool
+}
+
+// Scheme returns "https" or "http", the scheme the client used. Behind a TLS-terminating proxy
+// it's only known when RequireHTTPSMiddleware trusts the proxy's X-Forwarded-Proto.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) Scheme() string {
+	switch {
+	case ggreq.Request.TLS != nil:
+		return "https"
+	case ggreq.forwardedProto != "":
+		return ggreq.forwardedProto
+	default:
+		return "http"
+	}
+}
+
+// GetRequireHTTPSMiddleware rejects plain HTTP requests with 403, or redirects them to HTTPS with
+// cfg.Redirect: GET and HEAD with 301, other methods with 308 to keep the method and body.
+// X-Forwarded-Proto is used when the immediate peer is a trusted proxy, see GGRequest.Scheme.
+func GetRequireHTTPSMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg HTTPSConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("RequireHTTPSMiddleware start")
+			if ggreq.Request.TLS == nil && isTrustedProxy(remoteIP(ggreq.Request), cfg.TrustedProxies) {
+				proto, _, _ := strings.Cut(ggreq.Request.Header.Get("X-Forwarded-Proto"), ",")
+				proto = strings.ToLower(strings.TrimSpace(proto))
+				if proto == "http" || proto == "https" {
+					ggreq.forwardedProto = proto
+				}
+			}
+
+			if ggreq.Scheme() != "https" {
+				if !cfg.Redirect {
+					ggreq.Logger.Debug("RequireHTTPSMiddleware finish, rejected")
+					return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{Message: "HTTPS is required", StatusCode: http.StatusForbidden}
+				}
+				statusCode := http.StatusPermanentRedirect
+				if ggreq.Request.Method == http.MethodGet || ggreq.Request.Method == http.MethodHead {
+					statusCode = http.StatusMovedPermanently
+				}
+				ggresp := &GGResponse[TRespBody, TErrorData]{StatusCode: statusCode}
+				ggresp.SetLocation("https://" + ggreq.Request.Host + ggreq.Request.URL.RequestURI())
+				ggreq.Logger.Debug("RequireHTTPSMiddleware finish, redirected")
+				return ggresp, nil
+			}
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("RequireHTTPSMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireHTTPSMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            HTTPSConfig
+		method         string
+		url            string
+		remoteAddr     string
+		forwardedProto string
+		wantStatus     int
+		wantLocation   string
+	}{
+		{
+			name:       "TLS",
+			method:     http.MethodPost,
+			url:        "https://example.com/items",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "plain HTTP rejected",
+			method:     http.MethodGet,
+			url:        "http://example.com/items",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:         "GET redirected",
+			cfg:          HTTPSConfig{Redirect: true},
+			method:       http.MethodGet,
+			url:          "http://example.com/items?page=2",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "https://example.com/items?page=2",
+		},
+		{
+			name:         "HEAD redirected",
+			cfg:          HTTPSConfig{Redirect: true},
+			method:       http.MethodHead,
+			url:          "http://example.com/items",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "https://example.com/items",
+		},
+		{
+			name:         "POST redirected keeping the method",
+			cfg:          HTTPSConfig{Redirect: true},
+			method:       http.MethodPost,
+			url:          "http://example.com/items",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/items",
+		},
+		{
+			name:           "trusted proxy terminating TLS",
+			cfg:            HTTPSConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			method:         http.MethodGet,
+			url:            "http://example.com/items",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedProto: "https",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "trusted proxy forwarding plain HTTP",
+			cfg:            HTTPSConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			method:         http.MethodGet,
+			url:            "http://example.com/items",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedProto: "http",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "forged X-Forwarded-Proto",
+			cfg:            HTTPSConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			method:         http.MethodGet,
+			url:            "http://example.com/items",
+			remoteAddr:     "203.0.113.7:1234",
+			forwardedProto: "https",
+			wantStatus:     http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var scheme string
+			u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				scheme = ggreq.Scheme()
+				return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+			}, GetRequireHTTPSMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](tt.cfg))
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+
+			rec := serve(t, u, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if tt.wantStatus == http.StatusOK && scheme != "https" {
+				t.Errorf("handler saw scheme %q, want https", scheme)
+			}
+		})
+	}
+}
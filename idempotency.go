@@ -0,0 +1,110 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore keeps responses of requests by their idempotency keys.
+type IdempotencyStore interface {
+	Get(key string) (*CachedResponse, bool)
+	// Set stores the response, the store decides for how long to keep it.
+	Set(key string, resp *CachedResponse)
+}
+
+// MemoryIdempotencyStore keeps responses in memory for the TTL, it's the MemoryResponseStore.
+type MemoryIdempotencyStore = MemoryResponseStore
+
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return NewMemoryResponseStore(ttl)
+}
+
+func cloneHeaders(headers map[string][]string) map[string][]string {
+	cloned := make(map[string][]string, len(headers))
+	for headerName, headerValues := range headers {
+		cloned[headerName] = slices.Clone(headerValues)
+	}
+	return cloned
+}
+
+// GetIdempotencyMiddleware runs the handler once per Idempotency-Key header value and replays
+// the stored response for repeated requests, marking them with the Idempotent-Replayed header.
+// Keys are scoped by the request method and path. Requests with a key which is being processed
+// wait for the first one to finish. Middleware errors, 5xx and streamed responses are not stored,
+// so such requests may be retried. It stores the serialized body, so it has to be placed outside
+// DataProcessingMiddleware, and inside RequestIDMiddleware not to replay request IDs.
+func GetIdempotencyMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](store IdempotencyStore) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	var mu sync.Mutex
+	inFlight := make(map[string]chan struct{})
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("IdempotencyMiddleware start")
+			idempotencyKey := ggreq.Request.Header.Get("Idempotency-Key")
+			if idempotencyKey == "" {
+				ggreq.Logger.Debug("IdempotencyMiddleware finish, no key")
+				return hFunc(ggreq)
+			}
+			storeKey := ggreq.Request.Method + " " + ggreq.Request.URL.Path + " " + idempotencyKey
+
+			// The in-flight entry locks the key, mu only guards the map, so the store is
+			// accessed without blocking requests with other keys.
+			for {
+				mu.Lock()
+				done, busy := inFlight[storeKey]
+				if !busy {
+					inFlight[storeKey] = make(chan struct{})
+				}
+				mu.Unlock()
+				if !busy {
+					break
+				}
+
+				select {
+				case <-done:
+				case <-ggreq.Request.Context().Done():
+					return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{
+						Message:    "request with the same idempotency key is in progress",
+						StatusCode: http.StatusConflict,
+					}
+				}
+			}
+			defer func() {
+				mu.Lock()
+				close(inFlight[storeKey])
+				delete(inFlight, storeKey)
+				mu.Unlock()
+			}()
+
+			if cached, ok := store.Get(storeKey); ok {
+				headers := cloneHeaders(cached.Headers)
+				headers["Idempotent-Replayed"] = []string{"true"}
+				ggreq.Logger.Debug("IdempotencyMiddleware finish, replayed")
+				return &GGResponse[TRespBody, TErrorData]{
+					StatusCode:         cached.StatusCode,
+					Headers:            headers,
+					serializedResponse: cached.Body,
+				}, nil
+			}
+
+			ggresp, err := hFunc(ggreq)
+			if err != nil || ggresp == nil || ggresp.bodyWriter != nil {
+				return ggresp, err
+			}
+			statusCode, body := resolveResponse(ggresp, err)
+			if statusCode >= http.StatusInternalServerError {
+				return ggresp, err
+			}
+			store.Set(storeKey, &CachedResponse{
+				StatusCode: statusCode,
+				Headers:    cloneHeaders(ggresp.Headers),
+				Body:       body,
+			})
+
+			ggreq.Logger.Debug("IdempotencyMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newIdempotentRequest(key string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", key)
+	return req
+}
+
+func TestIdempotencyMiddlewareReplaysResponse(t *testing.T) {
+	var calls atomic.Int32
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		calls.Add(1)
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: "created"}, StatusCode: http.StatusCreated}, nil
+	}, GetIdempotencyMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](NewMemoryIdempotencyStore(time.Minute)))
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := serve(t, u, newIdempotentRequest("k1"))
+			if rec.Code != http.StatusCreated || rec.Body.String() != `{"value":"created"}` {
+				t.Errorf("response = %d %q", rec.Code, rec.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler called %d times, want 1", got)
+	}
+	if rec := serve(t, u, newIdempotentRequest("k1")); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("repeated request is not marked as replayed")
+	}
+}
+
+// blockingStore blocks Get of the key until unblock is closed.
+type blockingStore struct {
+	*MemoryResponseStore
+	key     string
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func (s *blockingStore) Get(key string) (*CachedResponse, bool) {
+	if key == s.key {
+		close(s.entered)
+		<-s.unblock
+	}
+	return s.MemoryResponseStore.Get(key)
+}
+
+func TestIdempotencyMiddlewareDoesNotLockOtherKeysDuringStoreAccess(t *testing.T) {
+	store := &blockingStore{
+		MemoryResponseStore: NewMemoryResponseStore(time.Minute),
+		key:                 "POST /orders slow",
+		entered:             make(chan struct{}),
+		unblock:             make(chan struct{}),
+	}
+	u := newTestHandler(okHandler("ok"), GetIdempotencyMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](store))
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		serve(t, u, newIdempotentRequest("slow"))
+	}()
+	<-store.entered
+
+	fastDone := make(chan struct{})
+	go func() {
+		defer close(fastDone)
+		serve(t, u, newIdempotentRequest("fast"))
+	}()
+	select {
+	case <-fastDone:
+	case <-time.After(5 * time.Second):
+		t.Error("request with another key is blocked by the store access")
+	}
+
+	close(store.unblock)
+	<-slowDone
+	<-fastDone
+}
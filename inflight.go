@@ -0,0 +1,52 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// GetMaxInFlightMiddleware limits the number of concurrently processed requests to n.
+// When the limit is reached, requests wait for a free slot up to waitTimeout and get 503 after that,
+// zero waitTimeout rejects them immediately. Slots are released even if the handler panics.
+// It panics if n is not positive, as such a limit would reject all requests.
+func GetMaxInFlightMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](n int, waitTimeout time.Duration) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if n <= 0 {
+		panic(fmt.Sprintf("gogohandlers: max in-flight requests must be positive, got %d", n))
+	}
+	semaphore := make(chan struct{}, n)
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("MaxInFlightMiddleware start")
+			acquired := false
+			select {
+			case semaphore <- struct{}{}:
+				acquired = true
+			default:
+				if waitTimeout > 0 {
+					timer := time.NewTimer(waitTimeout)
+					defer timer.Stop()
+					select {
+					case semaphore <- struct{}{}:
+						acquired = true
+					case <-timer.C:
+					case <-ggreq.Request.Context().Done():
+					}
+				}
+			}
+			if !acquired {
+				ggreq.Logger.Warn("Too many requests in flight")
+				return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{
+					Message:    http.StatusText(http.StatusServiceUnavailable),
+					StatusCode: http.StatusServiceUnavailable,
+				}
+			}
+			defer func() { <-semaphore }()
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("MaxInFlightMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
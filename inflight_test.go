@@ -0,0 +1,103 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxInFlightMiddlewareRejectsOverLimit(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		close(entered)
+		<-release
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	}, GetMaxInFlightMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](1, 0))
+
+	done := make(chan int)
+	go func() {
+		done <- serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil)).Code
+	}()
+	<-entered
+
+	if rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status over the limit = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("status within the limit = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestMaxInFlightMiddlewarePanicsOnInvalidLimit(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("n=%d: no panic", n)
+				}
+			}()
+			GetMaxInFlightMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](n, time.Second)
+		}()
+	}
+}
+
+func TestMaxInFlightMiddlewareReleasesSlotOnPanic(t *testing.T) {
+	panicking := true
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		if panicking {
+			panic("boom")
+		}
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	},
+		GetMaxInFlightMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](1, 0),
+		GetRecoveryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](),
+	)
+
+	if rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status of the panicking request = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	panicking = false
+	if rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusOK {
+		t.Errorf("status after the panic = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func BenchmarkMaxInFlightMiddleware(b *testing.B) {
+	const n = 4
+	var running, peak atomic.Int32
+	hFunc := GetMaxInFlightMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](n, time.Minute)(
+		func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				observed := peak.Load()
+				if current <= observed || peak.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Microsecond)
+			return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+		},
+	)
+	b.ReportAllocs()
+	b.SetParallelism(2 * n)
+	b.RunParallel(func(pb *testing.PB) {
+		ggreq := &GGRequest[testServiceProvider, testRequest, testGetParams]{
+			Request: httptest.NewRequest(http.MethodGet, "/", nil),
+			Logger:  discardLogger(),
+		}
+		for pb.Next() {
+			if _, err := hFunc(ggreq); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	if peak.Load() > n {
+		b.Errorf("%d handlers ran concurrently, want at most %d", peak.Load(), n)
+	}
+}
@@ -0,0 +1,37 @@
+package gogohandlers
+
+import "golang.org/x/text/language"
+
+// PreferredLanguages returns languages of the Accept-Language header, the most preferred first.
+// It returns nil when the header is absent or malformed.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) PreferredLanguages() []language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(ggreq.Request.Header.Get("Accept-Language"))
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
+// Localizable is implemented by error data with translatable messages, see
+// GetLocalizedErrorHandlingMiddleware. Localize gets a function translating
+// message codes, it returns an empty string when there's no translation:
+//
+//	func (d *ErrorData) Localize(translate func(code string) string) {
+//		if message := translate(d.Code); message != "" {
+//			d.Message = message
+//		}
+//	}
+type Localizable interface {
+	Localize(translate func(code string) string)
+}
+
+// localizeErrorData translates the error data message if it's Localizable.
+func localizeErrorData(errorData any, langs []language.Tag, localize func(code string, langs []language.Tag) string) {
+	localizable, ok := errorData.(Localizable)
+	if !ok {
+		return
+	}
+	localizable.Localize(func(code string) string {
+		return localize(code, langs)
+	})
+}
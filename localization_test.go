@@ -0,0 +1,69 @@
+package gogohandlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocalizedErrorHandlingMiddleware(t *testing.T) {
+	shared := &HTTPError{Code: "not_found", Message: "not found"}
+	errorHandler := func(err error, l *slog.Logger) (int, *HTTPError) {
+		return http.StatusNotFound, shared
+	}
+	translations := map[language.Tag]string{language.German: "nicht gefunden"}
+	localize := func(code string, langs []language.Tag) string {
+		for _, lang := range langs {
+			if message, ok := translations[lang]; ok && code == "not_found" {
+				return message
+			}
+		}
+		return ""
+	}
+
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, HTTPError], error) {
+		return nil, errors.New("missing")
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, HTTPError](nil)
+	u.Middlewares[0] = GetLocalizedErrorHandlingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse](localize, errorHandler)
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "de, en;q=0.5", want: `{"code":"not_found","message":"nicht gefunden"}`},
+		{acceptLanguage: "en", want: `{"code":"not_found","message":"not found"}`},
+		{acceptLanguage: "", want: `{"code":"not_found","message":"not found"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+
+		rec := serve(t, u, req)
+
+		if rec.Code != http.StatusNotFound || rec.Body.String() != tt.want {
+			t.Errorf("Accept-Language %q: %d %s, want 404 %s", tt.acceptLanguage, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+	if shared.Message != "not found" {
+		t.Errorf("shared error data was modified: %q", shared.Message)
+	}
+}
+
+func TestValidateMiddlewareOrderKnowsLocalizedErrorHandling(t *testing.T) {
+	localize := func(code string, langs []language.Tag) string { return "" }
+	middlewares := []testMiddleware{
+		GetDataProcessingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](nil),
+		GetLocalizedErrorHandlingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](localize),
+	}
+
+	if problems := ValidateMiddlewareOrder(middlewares, nil); len(problems) != 1 {
+		t.Errorf("problems = %q, want ErrorHandlingMiddleware wrapping DataProcessingMiddleware", problems)
+	}
+}
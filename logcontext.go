@@ -0,0 +1,45 @@
+package gogohandlers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LoggerFromContext returns the base logger with the request_id attribute of the request the
+// context belongs to, or the base logger itself outside of requests.
+func LoggerFromContext(ctx context.Context, base *slog.Logger) *slog.Logger {
+	if base == nil {
+		base = slog.Default()
+	}
+	requestID, ok := RequestIDFromContext(ctx)
+	if !ok {
+		return base
+	}
+	return base.With(slog.String("request_id", requestID))
+}
+
+// ContextHandler adds the request_id attribute to records logged with a request context,
+// e.g. by logger.InfoContext(ctx, ...). Records logged by ggreq.Logger already have it,
+// so the handler is meant for loggers living outside of requests, like the service provider's one.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,47 @@
+package gogohandlers
+
+import (
+	"time"
+)
+
+// MetricsRecorder receives per-request metrics. A Prometheus adapter can look like:
+//
+//	type PrometheusRecorder struct {
+//		Requests *prometheus.CounterVec   // labels: method, route, status
+//		Duration *prometheus.HistogramVec // labels: method, route
+//	}
+//
+//	func (p PrometheusRecorder) ObserveRequest(method, route string, status int, duration time.Duration) {
+//		p.Requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+//		p.Duration.WithLabelValues(method, route).Observe(duration.Seconds())
+//	}
+type MetricsRecorder interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+type NoopMetricsRecorder struct{}
+
+func (NoopMetricsRecorder) ObserveRequest(string, string, int, time.Duration) {}
+
+// GetMetricsMiddleware reports every request to the recorder. The route is the ServeMux pattern
+// the request matched, so it doesn't blow up label cardinality the way raw paths do.
+func GetMetricsMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](m MetricsRecorder) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if m == nil {
+		m = NoopMetricsRecorder{}
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("MetricsMiddleware start")
+			start := time.Now()
+			ggresp, err := hFunc(ggreq)
+			elapsed := time.Since(start)
+
+			statusCode, _ := resolveResponse(ggresp, err)
+			m.ObserveRequest(ggreq.Request.Method, ggreq.Request.Pattern, statusCode, elapsed)
+
+			ggreq.Logger.Debug("MetricsMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
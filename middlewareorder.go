@@ -0,0 +1,99 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"runtime"
+)
+
+var middlewareNamePattern = regexp.MustCompile(`gogohandlers\.(?:Get)?(\w+?Middleware)`)
+
+// middlewareName identifies middlewares built by this package by their function names,
+// e.g. "DataProcessingMiddleware" for GetDataProcessingMiddleware(...). Other middlewares
+// and generic functions used as values, like RequestIDMiddleware[...], are not identified.
+func middlewareName(middleware any) string {
+	function := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer())
+	if function == nil {
+		return ""
+	}
+	match := middlewareNamePattern.FindStringSubmatch(function.Name())
+	if match == nil {
+		return ""
+	}
+	if name, ok := middlewareAliases[match[1]]; ok {
+		return name
+	}
+	return match[1]
+}
+
+// middlewareAliases maps variants of middlewares to the names their order is checked by.
+var middlewareAliases = map[string]string{
+	"LocalizedErrorHandlingMiddleware": "ErrorHandlingMiddleware",
+}
+
+// middlewaresOutsideDataProcessing need serialized bodies or set headers on errors of DataProcessingMiddleware.
+var middlewaresOutsideDataProcessing = []string{
+	"AuthMiddleware",
+	"RateLimitMiddleware",
+	"CORSMiddleware",
+	"CompressionMiddleware",
+	"ETagMiddleware",
+	"CacheMiddleware",
+	"IdempotencyMiddleware",
+	"WrapHTTPMiddleware",
+	"SignatureMiddleware",
+	"AccessLogMiddleware",
+}
+
+// ValidateMiddlewareOrder reports known-bad orderings of middlewares built by this package,
+// logging each problem with the logger if it's not nil. Middlewares are given in the Uitzicht
+// order, the last one is the outermost. The canonical order is the one of DefaultMiddlewares:
+//
+//	ErrorHandling, DataProcessing, RequestLogging, RequestID
+//
+// RecoveryMiddleware with recovery handlers goes inside DataProcessingMiddleware (this is not
+// checked, as it may as well be the outermost one without them), while middlewares working with
+// raw or serialized bodies or headers (auth, rate limit, CORS, compression, ETag, caching,
+// idempotency, signature verification, access logs and wrapped net/http ones) go outside of it.
+// Caching and idempotency go inside RequestIDMiddleware.
+func ValidateMiddlewareOrder[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](middlewares []func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), logger *slog.Logger) []string {
+	positions := make(map[string]int)
+	for i, middleware := range middlewares {
+		name := middlewareName(middleware)
+		if _, seen := positions[name]; name != "" && !seen {
+			positions[name] = i
+		}
+	}
+	wraps := func(outer, inner string) bool {
+		outerPosition, outerOk := positions[outer]
+		innerPosition, innerOk := positions[inner]
+		return outerOk && innerOk && outerPosition > innerPosition
+	}
+
+	var problems []string
+	if wraps("ErrorHandlingMiddleware", "DataProcessingMiddleware") {
+		problems = append(problems, "ErrorHandlingMiddleware wraps DataProcessingMiddleware, error data it sets is not serialized")
+	}
+	for _, name := range middlewaresOutsideDataProcessing {
+		if wraps("DataProcessingMiddleware", name) {
+			problems = append(problems, fmt.Sprintf("DataProcessingMiddleware wraps %s, which has to be outside of it", name))
+		}
+	}
+	if wraps("RequestLoggingMiddleware", "RequestIDMiddleware") {
+		problems = append(problems, "RequestLoggingMiddleware wraps RequestIDMiddleware, logs miss the request ID")
+	}
+	for _, name := range []string{"CacheMiddleware", "IdempotencyMiddleware"} {
+		if wraps(name, "RequestIDMiddleware") {
+			problems = append(problems, fmt.Sprintf("%s wraps RequestIDMiddleware, request IDs are replayed", name))
+		}
+	}
+
+	if logger != nil {
+		for _, problem := range problems {
+			logger.Warn("Bad middleware order", slog.String("problem", problem))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,53 @@
+package gogohandlers
+
+import "fmt"
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageParams are the common pagination query parameters, embed it into GetParams:
+//
+//	type ListValuesParams struct {
+//		gogohandlers.PageParams
+//		Prefix string `schema:"prefix"`
+//	}
+//
+// Out of range values are answered with 400 by DataProcessingMiddleware with the Validate
+// setting, see Validatable. Zero Limit means DefaultPageLimit.
+type PageParams struct {
+	Limit  int    `schema:"limit"`
+	Offset int    `schema:"offset"`
+	Cursor string `schema:"cursor"`
+}
+
+// PageLimit returns the requested limit, DefaultPageLimit when it is not set.
+func (p *PageParams) PageLimit() int {
+	if p.Limit == 0 {
+		return DefaultPageLimit
+	}
+	return p.Limit
+}
+
+// Validate checks the bounds of the limit and the offset.
+func (p *PageParams) Validate() error {
+	fields := make(map[string]string)
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		fields["limit"] = fmt.Sprintf("must be between 0 (default) and %d", MaxPageLimit)
+	}
+	if p.Offset < 0 {
+		fields["offset"] = "must not be negative"
+	}
+	if len(fields) > 0 {
+		return ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// Paginated is the response envelope for list endpoints.
+type Paginated[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,53 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type pageGetParams struct {
+	PageParams
+}
+
+func newPageHandler(settings *DataProcessingMiddlewareSettings) *Uitzicht[testServiceProvider, testRequest, pageGetParams, testResponse, testErrorData] {
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, testRequest, pageGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, pageGetParams, testResponse, testErrorData](settings)
+	return u
+}
+
+func TestPageParamsValidation(t *testing.T) {
+	tests := []struct {
+		query    string
+		validate bool
+		want     int
+	}{
+		{query: "", validate: true, want: http.StatusOK},
+		{query: "?limit=0", validate: true, want: http.StatusOK},
+		{query: "?limit=100&offset=10", validate: true, want: http.StatusOK},
+		{query: "?limit=101", validate: true, want: http.StatusBadRequest},
+		{query: "?limit=-1", validate: true, want: http.StatusBadRequest},
+		{query: "?offset=-1", validate: true, want: http.StatusBadRequest},
+		{query: "?limit=101", validate: false, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		u := newPageHandler(&DataProcessingMiddlewareSettings{Validate: tt.validate})
+
+		rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/"+tt.query, nil))
+
+		if rec.Code != tt.want {
+			t.Errorf("%q with Validate=%v: status = %d, want %d", tt.query, tt.validate, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestPageLimitDefault(t *testing.T) {
+	if got := (&PageParams{}).PageLimit(); got != DefaultPageLimit {
+		t.Errorf("PageLimit() = %d, want %d", got, DefaultPageLimit)
+	}
+	if got := (&PageParams{Limit: 5}).PageLimit(); got != 5 {
+		t.Errorf("PageLimit() = %d, want 5", got)
+	}
+}
@@ -0,0 +1,126 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiterStore keeps rate limiting state for keys.
+type RateLimiterStore interface {
+	// Allow reports whether a request for the key may proceed, and if not, when to retry.
+	Allow(key string) (bool, time.Duration)
+}
+
+// minRateLimiterIdleTimeout is the least time a bucket is kept after its last request.
+const minRateLimiterIdleTimeout = time.Minute
+
+type rateLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// MemoryRateLimiterStore keeps a token bucket per key in memory. Buckets idle for longer than it
+// takes them to refill (at least a minute) are evicted, as a new bucket behaves the same way, so
+// memory is bounded by the number of keys seen recently.
+type MemoryRateLimiterStore struct {
+	limit       rate.Limit
+	burst       int
+	idleTimeout time.Duration
+	now         func() time.Time
+	mu          sync.Mutex
+	limiters    map[string]*rateLimiterEntry
+	lastSweep   time.Time
+}
+
+func NewMemoryRateLimiterStore(limit rate.Limit, burst int) *MemoryRateLimiterStore {
+	idleTimeout := minRateLimiterIdleTimeout
+	if limit > 0 && limit != rate.Inf {
+		if refill := time.Duration(float64(burst) / float64(limit) * float64(time.Second)); refill > idleTimeout {
+			idleTimeout = refill
+		}
+	}
+	return &MemoryRateLimiterStore{
+		limit:       limit,
+		burst:       burst,
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		limiters:    make(map[string]*rateLimiterEntry),
+	}
+}
+
+// sweep removes idle buckets, at most once per idle timeout. It must be called with mu held.
+func (s *MemoryRateLimiterStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idleTimeout {
+		return
+	}
+	s.lastSweep = now
+	maps.DeleteFunc(s.limiters, func(_ string, entry *rateLimiterEntry) bool {
+		return now.Sub(entry.lastSeen) > s.idleTimeout
+	})
+}
+
+func (s *MemoryRateLimiterStore) Allow(key string) (bool, time.Duration) {
+	now := s.now()
+	s.mu.Lock()
+	s.sweep(now)
+	entry, ok := s.limiters[key]
+	if !ok {
+		entry = &rateLimiterEntry{limiter: rate.NewLimiter(s.limit, s.burst)}
+		s.limiters[key] = entry
+	}
+	entry.lastSeen = now
+	limiter := entry.limiter
+	s.mu.Unlock()
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, 0
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// GetRateLimitMiddleware limits requests with an in-memory token bucket per key.
+// The key is the client IP when keyFn is nil, use ClientIPKey behind proxies.
+func GetRateLimitMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](limit rate.Limit, burst int, keyFn func(*http.Request) string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return GetRateLimitMiddlewareWithStore[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](NewMemoryRateLimiterStore(limit, burst), keyFn)
+}
+
+// GetRateLimitMiddlewareWithStore limits requests using the given store. Rejected requests get 429
+// with Retry-After, so the middleware should be placed outside DataProcessingMiddleware.
+func GetRateLimitMiddlewareWithStore[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](store RateLimiterStore, keyFn func(*http.Request) string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if keyFn == nil {
+		keyFn = ClientIPKey(nil)
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("RateLimitMiddleware start")
+			key := keyFn(ggreq.Request)
+			if allowed, retryAfter := store.Allow(key); !allowed {
+				ggreq.Logger.Info("Rate limit exceeded", slog.String("key", key))
+				ggresp := &GGResponse[TRespBody, TErrorData]{}
+				if retryAfter > 0 {
+					ggresp.Headers = map[string][]string{
+						"Retry-After": {strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))},
+					}
+				}
+				return ggresp, MiddlewareProcessingError{Message: http.StatusText(http.StatusTooManyRequests), StatusCode: http.StatusTooManyRequests}
+			}
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("RateLimitMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestMemoryRateLimiterStoreLimitsPerKey(t *testing.T) {
+	store := NewMemoryRateLimiterStore(rate.Every(time.Second), 1)
+
+	if ok, _ := store.Allow("a"); !ok {
+		t.Fatal("first request for a is rejected")
+	}
+	if ok, retryAfter := store.Allow("a"); ok || retryAfter <= 0 {
+		t.Errorf("second request for a = %v, %v, want rejected with retry delay", ok, retryAfter)
+	}
+	if ok, _ := store.Allow("b"); !ok {
+		t.Error("first request for b is rejected")
+	}
+}
+
+func TestMemoryRateLimiterStoreEvictsIdleKeys(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimiterStore(rate.Every(time.Second), 1)
+	store.now = func() time.Time { return now }
+
+	for i := range 100 {
+		store.Allow(fmt.Sprintf("10.0.0.%d", i))
+	}
+	now = now.Add(store.idleTimeout / 2)
+	store.Allow("recent")
+	now = now.Add(store.idleTimeout/2 + time.Second)
+	store.Allow("new")
+
+	if len(store.limiters) != 2 {
+		t.Errorf("%d buckets kept, want 2 (recent and new)", len(store.limiters))
+	}
+	if _, ok := store.limiters["recent"]; !ok {
+		t.Error("bucket used within the idle timeout was evicted")
+	}
+}
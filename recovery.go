@@ -0,0 +1,58 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// GetRecoveryMiddleware recovers from panics in the wrapped handler and turns them into a 500 response.
+// Recovery handlers are tried in order, the first one returning a non-zero status code defines the response.
+// To get the error data serialized, the middleware has to be placed inside DataProcessingMiddleware.
+// http.ErrAbortHandler is panicked again, so that the server aborts the response as usual.
+func GetRecoveryMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](recoveryHandlers ...func(recovered any, l *slog.Logger) (int, *TErrorData)) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (ggresp *GGResponse[TRespBody, TErrorData], err error) {
+			ggreq.Logger.Debug("RecoveryMiddleware start")
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					// net/http relies on this panic to abort the response silently.
+					panic(recovered)
+				}
+				ggreq.Logger.Error(
+					"Recovered from panic",
+					slog.String("panic", fmt.Sprint(recovered)),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				for _, recoveryHandlerFunc := range recoveryHandlers {
+					statusCode, errorData := recoveryHandlerFunc(recovered, ggreq.Logger)
+					if statusCode != 0 {
+						ggresp = &GGResponse[TRespBody, TErrorData]{
+							ErrorOccured: true,
+							ErrorData:    errorData,
+							StatusCode:   statusCode,
+						}
+						err = nil
+						return
+					}
+				}
+
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+				err = MiddlewareProcessingError{
+					Message:    http.StatusText(http.StatusInternalServerError),
+					StatusCode: http.StatusInternalServerError,
+				}
+			}()
+
+			ggresp, err = hFunc(ggreq)
+			ggreq.Logger.Debug("RecoveryMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
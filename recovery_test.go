@@ -0,0 +1,104 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func panickingHandler(value any) func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+	return func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		panic(value)
+	}
+}
+
+func TestRecoveryMiddlewareDefaultResponse(t *testing.T) {
+	u := newTestHandler(panickingHandler("boom"), GetRecoveryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]())
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if got := rec.Body.String(); got != http.StatusText(http.StatusInternalServerError) {
+		t.Errorf("body = %q, want the default error text", got)
+	}
+}
+
+func TestRecoveryMiddlewareCustomHandler(t *testing.T) {
+	var recoveredValue any
+	recoveryHandlers := []func(recovered any, l *slog.Logger) (int, *testErrorData){
+		func(any, *slog.Logger) (int, *testErrorData) {
+			return 0, nil
+		},
+		func(recovered any, l *slog.Logger) (int, *testErrorData) {
+			recoveredValue = recovered
+			return http.StatusServiceUnavailable, &testErrorData{Message: "try again later"}
+		},
+	}
+	u := newTestHandler(panickingHandler("boom"))
+	u.Middlewares = append([]testMiddleware{
+		GetRecoveryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse](recoveryHandlers...),
+	}, u.Middlewares...)
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if got := rec.Body.String(); got != `{"message":"try again later"}` {
+		t.Errorf("body = %s, want the recovery handler's error data", got)
+	}
+	if recoveredValue != "boom" {
+		t.Errorf("recovery handler got %v, want the panic value", recoveredValue)
+	}
+}
+
+func TestRecoveryMiddlewareRepanicsErrAbortHandler(t *testing.T) {
+	u := newTestHandler(panickingHandler(http.ErrAbortHandler), GetRecoveryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]())
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", recovered)
+		}
+	}()
+	serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("ServeHTTP returned normally")
+}
+
+func TestRecoveredPanicResponseHasRequestID(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestID   string
+		wantMatches func(string) bool
+	}{
+		{
+			name:        "incoming",
+			requestID:   "incoming-id",
+			wantMatches: func(id string) bool { return id == "incoming-id" },
+		},
+		{
+			name:        "generated",
+			wantMatches: func(id string) bool { return len(id) == 36 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(panickingHandler("boom"), GetRecoveryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData]())
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-Id", tt.requestID)
+			}
+
+			rec := serve(t, u, req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+			if got := rec.Header().Get("X-Request-Id"); !tt.wantMatches(got) {
+				t.Errorf("X-Request-Id = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package gogohandlers
+
+import "net/http"
+
+// OK builds a 200 response with the data.
+func OK[TRespBody, TErrorData any](data *TRespBody) *GGResponse[TRespBody, TErrorData] {
+	return &GGResponse[TRespBody, TErrorData]{ResponseData: data, StatusCode: http.StatusOK}
+}
+
+// Created builds a 201 response with the data.
+func Created[TRespBody, TErrorData any](data *TRespBody) *GGResponse[TRespBody, TErrorData] {
+	return &GGResponse[TRespBody, TErrorData]{ResponseData: data, StatusCode: http.StatusCreated}
+}
+
+// NoContent builds a 204 response without body.
+func NoContent[TRespBody, TErrorData any]() *GGResponse[TRespBody, TErrorData] {
+	return &GGResponse[TRespBody, TErrorData]{StatusCode: http.StatusNoContent}
+}
+
+// Err builds an error response with the status code and the error data.
+func Err[TRespBody, TErrorData any](status int, data *TErrorData) *GGResponse[TRespBody, TErrorData] {
+	return &GGResponse[TRespBody, TErrorData]{ErrorOccured: true, ErrorData: data, StatusCode: status}
+}
@@ -0,0 +1,70 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of handler calls, 3 by default.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for every next one, 100ms by default.
+	Backoff time.Duration
+	// ShouldRetry decides which handler errors are retried, all of them by default.
+	ShouldRetry func(err error) bool
+	// Methods are the request methods retries apply to, GET, HEAD and OPTIONS by default.
+	Methods []string
+}
+
+// GetRetryMiddleware calls the handler again when it returns an error matching ShouldRetry,
+// waiting with exponential backoff between attempts and giving up when the request context is done.
+// Handlers have to be idempotent, that's why only safe methods are retried by default.
+// Requests with a body are never retried: the body is consumed by the first attempt and can't be
+// re-read. Errors are detected as returned by the handler, so the middleware has to be placed inside
+// ErrorHandlingMiddleware.
+func GetRetryMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](cfg RetryConfig) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	if cfg.ShouldRetry == nil {
+		cfg.ShouldRetry = func(err error) bool { return true }
+	}
+	if cfg.Methods == nil {
+		cfg.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+	}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("RetryMiddleware start")
+			hasBody := ggreq.Request.Body != nil && ggreq.Request.Body != http.NoBody
+			if hasBody || !slices.Contains(cfg.Methods, ggreq.Request.Method) {
+				ggreq.Logger.Debug("RetryMiddleware finish, not retryable")
+				return hFunc(ggreq)
+			}
+
+			backoff := cfg.Backoff
+			for attempt := 1; ; attempt++ {
+				ggresp, err := hFunc(ggreq)
+				if err == nil || attempt >= cfg.MaxAttempts || !cfg.ShouldRetry(err) || ggreq.responseTakenOver {
+					ggreq.Logger.Debug("RetryMiddleware finish", slog.Int("attempts", attempt))
+					return ggresp, err
+				}
+				ggreq.Logger.Info("Retrying handler", slog.Int("attempt", attempt), slog.String("error", err.Error()))
+
+				timer := time.NewTimer(backoff)
+				select {
+				case <-timer.C:
+				case <-ggreq.Request.Context().Done():
+					timer.Stop()
+					return ggresp, err
+				}
+				backoff *= 2
+			}
+		}
+	}
+}
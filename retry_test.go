@@ -0,0 +1,44 @@
+package gogohandlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *http.Request
+		wantCode  int
+		wantCalls int
+	}{
+		{name: "GET", req: httptest.NewRequest(http.MethodGet, "/", nil), wantCode: http.StatusOK, wantCalls: 3},
+		{name: "POST", req: httptest.NewRequest(http.MethodPost, "/", nil), wantCode: http.StatusInternalServerError, wantCalls: 1},
+		{name: "GET with body", req: httptest.NewRequest(http.MethodGet, "/", strings.NewReader("{}")), wantCode: http.StatusInternalServerError, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				calls++
+				if calls <= 2 {
+					return nil, errors.New("downstream is flaky")
+				}
+				return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: "ok"}}, nil
+			})
+			retry := GetRetryMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](RetryConfig{Backoff: time.Millisecond})
+			u.Middlewares = slices.Concat([]testMiddleware{retry}, u.Middlewares)
+
+			rec := serve(t, u, tt.req)
+
+			if rec.Code != tt.wantCode || calls != tt.wantCalls {
+				t.Errorf("status = %d after %d calls, want %d after %d", rec.Code, calls, tt.wantCode, tt.wantCalls)
+			}
+		})
+	}
+}
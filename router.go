@@ -0,0 +1,283 @@
+package gogohandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultMiddlewares returns the standard middleware chain. Uitzicht applies middlewares
+// in order, so the last one is the outermost:
+//   - RequestIDMiddleware goes first, so everything inside can see the request ID;
+//   - RequestLoggingMiddleware tags the logger with that ID and measures the whole processing;
+//   - DataProcessingMiddleware decodes the request and serializes whatever comes back,
+//     including the error data set by the error handling middleware;
+//   - ErrorHandlingMiddleware is the innermost, it converts handler errors into error data.
+func DefaultMiddlewares[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](settings *DataProcessingMiddlewareSettings, errorHandlers ...func(err error, l *slog.Logger) (int, *TErrorData)) []func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return []func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error){
+		GetErrorHandlingMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](errorHandlers...),
+		GetDataProcessingMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](settings, errorHandlers...),
+		GetRequestLoggingMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](LoggingConfig{}),
+		GetRequestIDMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](RequestIDConfig{}),
+	}
+}
+
+// NewHandler builds an Uitzicht with DefaultMiddlewares using default settings and no error handlers.
+func NewHandler[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](sp *TServiceProvider, hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), logger *slog.Logger) *Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData] {
+	return &Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData]{
+		ServiceProvider: sp,
+		HandlerFunc:     hFunc,
+		Middlewares:     DefaultMiddlewares[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](nil),
+		Logger:          logger,
+	}
+}
+
+// Register adds the handler to the mux under the pattern, which follows http.ServeMux syntax,
+// optionally with a method and wildcards, like "GET /get_value/{key}". Only u.Middlewares are
+// applied, nothing is added by Register itself.
+func Register[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](mux *http.ServeMux, pattern string, u *Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData]) {
+	mux.Handle(pattern, u)
+}
+
+// Router registers handlers sharing the service provider, logger and default middlewares settings.
+// Routes are added with Handle, which infers type parameters from the handler function:
+//
+//	router := NewRouter(mux, storage, logger, nil, HandleErrors)
+//	Handle(router, http.MethodGet, "/ping", HandlePing)
+//	Handle(router, http.MethodPost, "/set_value", HandleSetValue)
+type Router[TServiceProvider ServiceProvider, TErrorData any] struct {
+	Mux             *http.ServeMux
+	ServiceProvider *TServiceProvider
+	Logger          *slog.Logger
+	Settings        *DataProcessingMiddlewareSettings
+	ErrorHandlers   []func(err error, l *slog.Logger) (int, *TErrorData)
+	// ServeMux answers HEAD requests with GET handlers, the body is not sent while headers,
+	// including Content-Length, are kept. DisableAutoHEAD makes such requests get 405 instead.
+	// Explicitly registered HEAD handlers take precedence regardless of it.
+	DisableAutoHEAD bool
+	// CORS, when set, is used to answer preflight requests to the router's paths, actual requests
+	// still need GetCORSMiddleware with the same config. Plain OPTIONS
+	// requests are answered with 204 and the Allow header regardless of it. Handlers explicitly
+	// registered for OPTIONS take precedence over both.
+	CORS *CORSConfig
+
+	prefix      string
+	middlewares []GroupMiddleware[TServiceProvider, TErrorData]
+	routes      *routeTable
+}
+
+// GroupMiddleware is a GG middleware applied by Router.Group to all routes of the group. Routes have
+// their own request and response types, so group middlewares are instantiated with any:
+//
+//	admin := router.Group("/admin", GetAuthMiddleware[Storage, any, any, any, ErrorData](authenticate, ""))
+//
+// Group middlewares wrap DataProcessingMiddleware, so they see the request before RequestData and
+// GetParams are decoded and the response after it is serialized, with nil ResponseData.
+type GroupMiddleware[TServiceProvider ServiceProvider, TErrorData any] func(func(*GGRequest[TServiceProvider, any, any]) (*GGResponse[any, TErrorData], error)) func(*GGRequest[TServiceProvider, any, any]) (*GGResponse[any, TErrorData], error)
+
+// adaptGroupMiddleware instantiates the group middleware for the route's types. The request and
+// the response are passed to the middleware as copies with erased types, changes made to them
+// are copied back.
+func adaptGroupMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](middleware GroupMiddleware[TServiceProvider, TErrorData]) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			var ggresp *GGResponse[TRespBody, TErrorData]
+			var erasedResp *GGResponse[any, TErrorData]
+			next := func(erasedReq *GGRequest[TServiceProvider, any, any]) (*GGResponse[any, TErrorData], error) {
+				copyRequestState(ggreq, erasedReq)
+				var err error
+				ggresp, err = hFunc(ggreq)
+				copyRequestState(erasedReq, ggreq)
+				if ggresp == nil {
+					return nil, err
+				}
+				erasedResp = &GGResponse[any, TErrorData]{}
+				copyResponseState(erasedResp, ggresp)
+				return erasedResp, err
+			}
+
+			erasedReq := &GGRequest[TServiceProvider, any, any]{}
+			copyRequestState(erasedReq, ggreq)
+			resp, err := middleware(next)(erasedReq)
+			copyRequestState(ggreq, erasedReq)
+			if resp == nil {
+				return nil, err
+			}
+			if resp != erasedResp || ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+			}
+			copyResponseState(ggresp, resp)
+			return ggresp, err
+		}
+	}
+}
+
+// copyRequestState copies the request fields which don't depend on the request types.
+func copyRequestState[TServiceProvider ServiceProvider, TDstReqBody, TDstGetParams, TSrcReqBody, TSrcGetParams any](dst *GGRequest[TServiceProvider, TDstReqBody, TDstGetParams], src *GGRequest[TServiceProvider, TSrcReqBody, TSrcGetParams]) {
+	dst.ServiceProvider = src.ServiceProvider
+	dst.Request = src.Request
+	dst.Logger = src.Logger
+	dst.Values = src.Values
+	dst.MultipartForm = src.MultipartForm
+	dst.responseWriter = src.responseWriter
+	dst.responseTakenOver = src.responseTakenOver
+	dst.bufferedBody = src.bufferedBody
+	dst.bodyBuffered = src.bodyBuffered
+	dst.forwardedProto = src.forwardedProto
+}
+
+// copyResponseState copies the response fields which don't depend on the response type.
+func copyResponseState[TDstRespBody, TSrcRespBody, TErrorData any](dst *GGResponse[TDstRespBody, TErrorData], src *GGResponse[TSrcRespBody, TErrorData]) {
+	dst.ErrorOccured = src.ErrorOccured
+	dst.ErrorData = src.ErrorData
+	dst.StatusCode = src.StatusCode
+	dst.Headers = src.Headers
+	dst.Body = src.Body
+	dst.ContentType = src.ContentType
+	dst.RawBody = src.RawBody
+	dst.serializedResponse = src.serializedResponse
+	dst.bodyWriter = src.bodyWriter
+}
+
+// routeTable keeps methods registered per pattern path, it is shared by a router and its groups.
+type routeTable struct {
+	mu     sync.Mutex
+	routes map[string]*route
+}
+
+type route struct {
+	methods   []string
+	anyMethod http.Handler
+	cors      *CORSConfig
+}
+
+// add records the method for the path. For the first method of the path it registers a handler
+// matching any method, which serves the method-less handler if there is one, answers OPTIONS
+// requests with 204 and other ones with 405.
+func (table *routeTable) add(mux *http.ServeMux, path string, methods []string, handler http.Handler, cors *CORSConfig) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	r, ok := table.routes[path]
+	if !ok {
+		r = &route{}
+		table.routes[path] = r
+		mux.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			table.serveUnmatched(path, w, req)
+		}))
+	}
+	if cors != nil {
+		r.cors = cors
+	}
+	if len(methods) == 0 {
+		r.anyMethod = handler
+		return
+	}
+	for _, method := range methods {
+		if !slices.Contains(r.methods, method) {
+			r.methods = append(r.methods, method)
+		}
+	}
+	slices.Sort(r.methods)
+}
+
+func (table *routeTable) serveUnmatched(path string, w http.ResponseWriter, req *http.Request) {
+	table.mu.Lock()
+	r := table.routes[path]
+	anyMethod, cors := r.anyMethod, r.cors
+	methods := slices.Clone(r.methods)
+	if !slices.Contains(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	allowed := strings.Join(methods, ", ")
+	table.mu.Unlock()
+
+	switch {
+	case anyMethod != nil:
+		anyMethod.ServeHTTP(w, req)
+	case req.Method == http.MethodOptions:
+		if cors != nil && isPreflightRequest(req) {
+			for headerName, headerValues := range cors.headers(req, true) {
+				w.Header()[headerName] = headerValues
+			}
+		}
+		w.Header().Set("Allow", allowed)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", allowed)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// Group returns a router registering handlers under the prefix, wrapped with the given middlewares.
+// Middlewares are applied in a fixed order: the parent's ones first (outermost), then the group's,
+// then the route's ones passed to Handle. All of them are inside the logging and request ID ones.
+func (router *Router[TServiceProvider, TErrorData]) Group(prefix string, middlewares ...GroupMiddleware[TServiceProvider, TErrorData]) *Router[TServiceProvider, TErrorData] {
+	group := *router
+	group.prefix = router.prefix + prefix
+	group.middlewares = slices.Concat(router.middlewares, middlewares)
+	return &group
+}
+
+func NewRouter[TServiceProvider ServiceProvider, TErrorData any](mux *http.ServeMux, sp *TServiceProvider, logger *slog.Logger, settings *DataProcessingMiddlewareSettings, errorHandlers ...func(err error, l *slog.Logger) (int, *TErrorData)) *Router[TServiceProvider, TErrorData] {
+	return &Router[TServiceProvider, TErrorData]{
+		Mux:             mux,
+		ServiceProvider: sp,
+		Logger:          logger,
+		Settings:        settings,
+		ErrorHandlers:   errorHandlers,
+		routes:          &routeTable{routes: make(map[string]*route)},
+	}
+}
+
+// Handle registers the handler for the method and pattern with DefaultMiddlewares. Route middlewares
+// wrap DataProcessingMiddleware and are wrapped by the group ones, then by the logging and request ID ones.
+// An empty method matches any method. Requests to the pattern with other methods get 405 with
+// the Allow header listing the registered ones, so the router has to own all patterns of a path
+// and the mux must not have method-less handlers for it.
+func Handle[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](router *Router[TServiceProvider, TErrorData], method, pattern string, hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), middlewares ...func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) *Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData] {
+	defaultMiddlewares := DefaultMiddlewares[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData](router.Settings, router.ErrorHandlers...)
+	groupMiddlewares := make([]func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error), 0, len(router.middlewares))
+	for i := len(router.middlewares) - 1; i >= 0; i-- {
+		groupMiddlewares = append(groupMiddlewares, adaptGroupMiddleware[TServiceProvider, TReqBody, TGetParams, TRespBody](router.middlewares[i]))
+	}
+	allMiddlewares := slices.Concat(defaultMiddlewares[:2], middlewares, groupMiddlewares, defaultMiddlewares[2:])
+	ValidateMiddlewareOrder(allMiddlewares, router.Logger)
+
+	u := &Uitzicht[TServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData]{
+		ServiceProvider: router.ServiceProvider,
+		HandlerFunc:     hFunc,
+		Middlewares:     allMiddlewares,
+		Logger:          router.Logger,
+	}
+
+	if router.routes == nil {
+		router.routes = &routeTable{routes: make(map[string]*route)}
+	}
+	path := router.prefix + pattern
+
+	var handler http.Handler = u
+	if method == http.MethodGet && router.DisableAutoHEAD {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				router.routes.serveUnmatched(path, w, r)
+				return
+			}
+			u.ServeHTTP(w, r)
+		})
+	}
+
+	switch {
+	case method == "":
+		router.routes.add(router.Mux, path, nil, handler, router.CORS)
+	case method == http.MethodGet && !router.DisableAutoHEAD:
+		router.routes.add(router.Mux, path, []string{http.MethodGet, http.MethodHead}, nil, router.CORS)
+		router.Mux.Handle(method+" "+path, handler)
+	default:
+		router.routes.add(router.Mux, path, []string{method}, nil, router.CORS)
+		router.Mux.Handle(method+" "+path, handler)
+	}
+	return u
+}
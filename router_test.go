@@ -0,0 +1,153 @@
+package gogohandlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func authenticateTestToken(r *http.Request) (any, error) {
+	token, ok := BearerToken(r)
+	if !ok || token != "secret" {
+		return nil, errors.New("invalid token")
+	}
+	return "admin", nil
+}
+
+func TestRouterGroupAppliesAuthToItsRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	router := NewRouter[testServiceProvider, testErrorData](mux, &testServiceProvider{}, discardLogger(), nil)
+	admin := router.Group("/admin", GetAuthMiddleware[testServiceProvider, any, any, any, testErrorData](authenticateTestToken, "Bearer"))
+
+	Handle(admin, http.MethodGet, "/users", func(ggreq *GGRequest[testServiceProvider, struct{}, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		principal, _ := Principal[string](ggreq.Values)
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: principal}}, nil
+	})
+	Handle(admin, http.MethodPost, "/users", func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[[]testResponse, testErrorData], error) {
+		return &GGResponse[[]testResponse, testErrorData]{ResponseData: &[]testResponse{{Value: ggreq.RequestData.Value}}}, nil
+	})
+	Handle(router, http.MethodGet, "/ping", okHandler("pong"))
+
+	newRequest := func(method, target, token string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		rec := serve(t, mux, newRequest(method, "/admin/users", ""))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s without token: status = %d, want %d", method, rec.Code, http.StatusUnauthorized)
+		}
+		if got := rec.Header().Get("WWW-Authenticate"); got != "Bearer" {
+			t.Errorf("%s without token: WWW-Authenticate = %q, want %q", method, got, "Bearer")
+		}
+	}
+
+	rec := serve(t, mux, newRequest(http.MethodGet, "/admin/users", "secret"))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"value":"admin"}` {
+		t.Errorf("GET with token: %d %q", rec.Code, rec.Body.String())
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/users", strings.NewReader(`{"value":"v"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = serve(t, mux, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != `[{"value":"v"}]` {
+		t.Errorf("POST with token: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(t, mux, newRequest(http.MethodGet, "/ping", ""))
+	if rec.Code != http.StatusOK {
+		t.Errorf("route outside the group: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRouterGroupMiddlewareOrder(t *testing.T) {
+	var calls []string
+	groupMiddleware := func(name string) GroupMiddleware[testServiceProvider, testErrorData] {
+		return func(hFunc func(*GGRequest[testServiceProvider, any, any]) (*GGResponse[any, testErrorData], error)) func(*GGRequest[testServiceProvider, any, any]) (*GGResponse[any, testErrorData], error) {
+			return func(ggreq *GGRequest[testServiceProvider, any, any]) (*GGResponse[any, testErrorData], error) {
+				calls = append(calls, name)
+				return hFunc(ggreq)
+			}
+		}
+	}
+	routeMiddleware := func(hFunc func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error)) func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+			calls = append(calls, "route")
+			return hFunc(ggreq)
+		}
+	}
+
+	mux := http.NewServeMux()
+	router := NewRouter[testServiceProvider, testErrorData](mux, &testServiceProvider{}, discardLogger(), nil)
+	parent := router.Group("/api", groupMiddleware("parent"))
+	group := parent.Group("/v1", groupMiddleware("group"))
+	Handle(group, http.MethodGet, "/items", okHandler("ok"), routeMiddleware)
+
+	rec := serve(t, mux, httptest.NewRequest(http.MethodGet, "/api/v1/items", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if want := []string{"parent", "group", "route"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestRouterAnswersOptions(t *testing.T) {
+	mux := http.NewServeMux()
+	router := NewRouter[testServiceProvider, testErrorData](mux, &testServiceProvider{}, discardLogger(), nil)
+	router.CORS = &CORSConfig{AllowedOrigins: []string{"https://example.com"}, AllowedMethods: []string{http.MethodGet, http.MethodPost}}
+	Handle(router, http.MethodGet, "/items", okHandler("ok"))
+	Handle(router, http.MethodPost, "/items", okHandler("ok"))
+
+	rec := serve(t, mux, httptest.NewRequest(http.MethodOptions, "/items", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("plain OPTIONS: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got, want := rec.Header().Get("Allow"), "GET, HEAD, POST, OPTIONS"; got != want {
+		t.Errorf("plain OPTIONS: Allow = %q, want %q", got, want)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("plain OPTIONS: Access-Control-Allow-Origin = %q, want none", got)
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec = serve(t, mux, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("preflight: Access-Control-Allow-Origin = %q, want the origin", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got == "" {
+		t.Error("preflight: Access-Control-Allow-Methods is not set")
+	}
+}
+
+func TestRouterAllowListsExplicitOptionsOnce(t *testing.T) {
+	mux := http.NewServeMux()
+	router := NewRouter[testServiceProvider, testErrorData](mux, &testServiceProvider{}, discardLogger(), nil)
+	Handle(router, http.MethodGet, "/items", okHandler("ok"))
+	Handle(router, http.MethodOptions, "/items", okHandler("options"))
+
+	rec := serve(t, mux, httptest.NewRequest(http.MethodOptions, "/items", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"value":"options"}` {
+		t.Errorf("explicit OPTIONS: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(t, mux, httptest.NewRequest(http.MethodDelete, "/items", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got, want := rec.Header().Get("Allow"), "GET, HEAD, OPTIONS"; got != want {
+		t.Errorf("DELETE: Allow = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeadersMiddlewareComposesWithCORS(t *testing.T) {
+	cfg := DefaultSecurityHeadersConfig()
+	cfg.ContentSecurityPolicy = "default-src 'self'"
+	u := newTestHandler(okHandler("ok"),
+		GetSecurityHeadersMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](cfg),
+		GetCORSMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](CORSConfig{AllowedOrigins: []string{"https://example.com"}}),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+
+	rec := serve(t, u, req)
+
+	want := map[string]string{
+		"X-Content-Type-Options":      "nosniff",
+		"X-Frame-Options":             "DENY",
+		"Referrer-Policy":             "no-referrer",
+		"Content-Security-Policy":     "default-src 'self'",
+		"Access-Control-Allow-Origin": "https://example.com",
+	}
+	for headerName, headerValue := range want {
+		if got := rec.Header().Get(headerName); got != headerValue {
+			t.Errorf("%s = %q, want %q", headerName, got, headerValue)
+		}
+	}
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want it omitted", got)
+	}
+}
+
+func TestSecurityHeadersMiddlewareKeepsHandlerHeaders(t *testing.T) {
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{
+			ResponseData: &testResponse{},
+			Headers:      map[string][]string{"x-frame-options": {"SAMEORIGIN"}},
+		}, nil
+	}, GetSecurityHeadersMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](DefaultSecurityHeadersConfig()))
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Values("X-Frame-Options"); len(got) != 1 || got[0] != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want the handler's one", got)
+	}
+}
@@ -0,0 +1,342 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/schema"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+type Serializer interface {
+	Marshal(v any) ([]byte, error)
+	ContentType() string
+}
+
+// StreamingSerializer is implemented by serializers able to encode straight into a writer.
+// It is used by DataProcessingMiddleware when StreamResponse is enabled.
+type StreamingSerializer interface {
+	Serializer
+	Encode(w io.Writer, v any) error
+}
+
+type JSONSerializer struct {
+	// DisableHTMLEscape keeps <, > and & in strings as is instead of escaping them.
+	DisableHTMLEscape bool
+	// Indent makes the output indented with the given string.
+	Indent string
+}
+
+func (s JSONSerializer) Marshal(v any) ([]byte, error) {
+	if !s.DisableHTMLEscape && s.Indent == "" {
+		return json.Marshal(v)
+	}
+	var buf bytes.Buffer
+	if err := s.Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (s JSONSerializer) Encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(!s.DisableHTMLEscape)
+	encoder.SetIndent("", s.Indent)
+	return encoder.Encode(v)
+}
+
+func (s JSONSerializer) ContentType() string {
+	return "application/json"
+}
+
+// XMLSerializer encodes responses with encoding/xml. XML needs a root element, so values other than
+// structs with an XMLName field are wrapped into the RootName element, "response" by default.
+// Slice elements are encoded as "item" elements inside of it.
+type XMLSerializer struct {
+	RootName string
+}
+
+func (s XMLSerializer) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s XMLSerializer) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() == reflect.Struct {
+		if _, ok := value.Type().FieldByName("XMLName"); ok {
+			return encoder.Encode(v)
+		}
+	}
+
+	rootName := s.RootName
+	if rootName == "" {
+		rootName = "response"
+	}
+	root := xml.StartElement{Name: xml.Name{Local: rootName}}
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return encoder.EncodeElement(v, root)
+	}
+	if err := encoder.EncodeToken(root); err != nil {
+		return err
+	}
+	for i := range value.Len() {
+		if err := encoder.EncodeElement(value.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func (s XMLSerializer) ContentType() string {
+	return "application/xml"
+}
+
+// YAMLSerializer encodes responses with gopkg.in/yaml.v3. Fields are named by `yaml` struct tags,
+// json ones are ignored, so untagged fields become lowercased field names.
+type YAMLSerializer struct{}
+
+func (s YAMLSerializer) Marshal(v any) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (s YAMLSerializer) Encode(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func (s YAMLSerializer) ContentType() string {
+	return "application/yaml"
+}
+
+// MsgpackSerializer encodes responses with MessagePack. Fields are named by `msgpack` struct tags,
+// falling back to `json` ones, so the same structs serve both formats.
+type MsgpackSerializer struct{}
+
+func (s MsgpackSerializer) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s MsgpackSerializer) Encode(w io.Writer, v any) error {
+	encoder := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(encoder)
+	encoder.Reset(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(v)
+}
+
+func (s MsgpackSerializer) ContentType() string {
+	return "application/msgpack"
+}
+
+// negotiateSerializer picks the serializer for the media type preferred by the Accept header.
+// Wildcards and media types absent in serializers are skipped.
+func negotiateSerializer(accept string, serializers map[string]Serializer) (Serializer, bool) {
+	var chosen Serializer
+	chosenQuality := 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		serializer, ok := serializers[mediaType]
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > chosenQuality {
+			chosen, chosenQuality = serializer, quality
+		}
+	}
+	return chosen, chosen != nil
+}
+
+type Deserializer interface {
+	Deserialize(r *http.Request, v any) error
+}
+
+// JSONDeserializer decodes JSON bodies. Absent fields keep their zero values, so use pointer
+// fields to tell an absent field (nil) from a zero one. With RejectMissingRequired, fields
+// tagged with the ",required" option, e.g. `json:"value,required"`, must be present in the body,
+// while null is accepted.
+type JSONDeserializer struct {
+	RejectMissingRequired bool
+	// DisallowUnknownFields makes fields absent in the destination struct an error.
+	DisallowUnknownFields bool
+}
+
+func (d JSONDeserializer) decode(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	if d.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+func (d JSONDeserializer) Deserialize(r *http.Request, v any) error {
+	if !d.RejectMissingRequired {
+		return d.decode(r.Body, v)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return err
+	}
+	if err := d.decode(bytes.NewReader(raw), v); err != nil {
+		return err
+	}
+	required := requiredJSONFields(reflect.TypeOf(v).Elem())
+	if len(required) == 0 {
+		return nil
+	}
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &present); err != nil {
+		return err
+	}
+	for _, name := range required {
+		if !hasJSONKey(present, name) {
+			return DecodeError{Kind: DecodeErrorMissingField, Field: name}
+		}
+	}
+	return nil
+}
+
+// hasJSONKey reports whether the object has the key, matching case-insensitively as encoding/json does.
+func hasJSONKey(object map[string]json.RawMessage, name string) bool {
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredJSONFields returns JSON names of the struct fields tagged with the ",required" option.
+func requiredJSONFields(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var required []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("json")
+		if !hasTag && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			required = append(required, requiredJSONFields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" || !slices.Contains(strings.Split(options, ","), "required") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		required = append(required, name)
+	}
+	return required
+}
+
+// FormDeserializer decodes application/x-www-form-urlencoded bodies with the Decoder,
+// DataProcessingMiddleware uses the GetParams one, so fields are named by the same tag.
+// Only body values are decoded, query parameters go to GetParams.
+type FormDeserializer struct {
+	Decoder *schema.Decoder
+}
+
+func (d FormDeserializer) Deserialize(r *http.Request, v any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return nil
+	}
+	return d.Decoder.Decode(v, r.PostForm)
+}
+
+// MultipartDeserializer parses multipart/form-data bodies, keeping up to MaxMemory bytes of
+// files in memory and the rest in temporary files. Values are decoded with the Decoder,
+// the parsed form including files is available as GGRequest.MultipartForm.
+type MultipartDeserializer struct {
+	Decoder   *schema.Decoder
+	MaxMemory int64
+}
+
+func (d MultipartDeserializer) Deserialize(r *http.Request, v any) error {
+	if err := r.ParseMultipartForm(d.MaxMemory); err != nil {
+		return err
+	}
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return nil
+	}
+	return d.Decoder.Decode(v, r.MultipartForm.Value)
+}
+
+// MsgpackDeserializer decodes MessagePack bodies, naming fields the same way as MsgpackSerializer.
+type MsgpackDeserializer struct{}
+
+func (d MsgpackDeserializer) Deserialize(r *http.Request, v any) error {
+	decoder := msgpack.NewDecoder(r.Body)
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+const ndjsonFlushInterval = 100 * time.Millisecond
+
+// writeNDJSON encodes items one per line, flushing the writer at most every ndjsonFlushInterval
+// and after the last item.
+func writeNDJSON[T any](w io.Writer, items iter.Seq2[T, error], serializer StreamingSerializer) error {
+	flusher, _ := w.(http.Flusher)
+	lastFlush := time.Now()
+	for item, err := range items {
+		if err != nil {
+			return err
+		}
+		if err := serializer.Encode(w, item); err != nil {
+			return err
+		}
+		if flusher != nil && time.Since(lastFlush) >= ndjsonFlushInterval {
+			flusher.Flush()
+			lastFlush = time.Now()
+		}
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONSerializerHTMLEscape(t *testing.T) {
+	value := map[string]string{"q": "a&b<c>"}
+	tests := []struct {
+		serializer JSONSerializer
+		want       string
+	}{
+		{serializer: JSONSerializer{}, want: `{"q":"a\u0026b\u003cc\u003e"}`},
+		{serializer: JSONSerializer{DisableHTMLEscape: true}, want: `{"q":"a&b<c>"}`},
+		{serializer: JSONSerializer{DisableHTMLEscape: true, Indent: "  "}, want: "{\n  \"q\": \"a&b<c>\"\n}"},
+	}
+	for _, tt := range tests {
+		got, err := tt.serializer.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%+v: Marshal = %s, want %s", tt.serializer, got, tt.want)
+		}
+	}
+}
+
+type optionalValueRequest struct {
+	Key   string  `json:"key,required"`
+	Value *string `json:"value"`
+}
+
+func TestJSONDeserializerMissingFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		deserializer JSONDeserializer
+		wantErr      bool
+		wantValue    *string
+	}{
+		{name: "absent pointer field", body: `{"key":"k"}`},
+		{name: "null pointer field", body: `{"key":"k","value":null}`},
+		{name: "present pointer field", body: `{"key":"k","value":""}`, wantValue: new(string)},
+		{name: "missing required field", body: `{"value":"v"}`, deserializer: JSONDeserializer{RejectMissingRequired: true}, wantErr: true},
+		{name: "present required field", body: `{"key":"k"}`, deserializer: JSONDeserializer{RejectMissingRequired: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req optionalValueRequest
+			err := tt.deserializer.Deserialize(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)), &req)
+
+			if tt.wantErr {
+				var decodeErr DecodeError
+				if !errors.As(err, &decodeErr) || decodeErr.Kind != DecodeErrorMissingField || decodeErr.Field != "key" {
+					t.Errorf("error = %v, want missing key", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (req.Value == nil) != (tt.wantValue == nil) || (req.Value != nil && *req.Value != *tt.wantValue) {
+				t.Errorf("value = %v, want %v", req.Value, tt.wantValue)
+			}
+		})
+	}
+}
+
+type formRequest struct {
+	Key   string `schema:"key"`
+	Value string `schema:"value"`
+}
+
+func TestFormBodyDecoding(t *testing.T) {
+	var got *formRequest
+	u := NewHandler(&testServiceProvider{}, func(ggreq *GGRequest[testServiceProvider, formRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		got = ggreq.RequestData
+		return nil, nil
+	}, discardLogger())
+	req := httptest.NewRequest(http.MethodPost, "/set_value?key=ignored", strings.NewReader("key=k&value=v"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := serve(t, u, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d %q", rec.Code, rec.Body.String())
+	}
+	if got.Key != "k" || got.Value != "v" {
+		t.Errorf("request data = %+v, want key k and value v", *got)
+	}
+}
+
+type pingResponse struct {
+	Message string `json:"message" xml:"message" yaml:"message"`
+	Count   int    `json:"count" xml:"count" yaml:"count"`
+}
+
+type namedPingResponse struct {
+	XMLName xml.Name `xml:"ping"`
+	Message string   `xml:"message"`
+}
+
+func TestXMLSerializer(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{name: "wrapped struct", value: &pingResponse{Message: "pong", Count: 1}, want: "<response><message>pong</message><count>1</count></response>"},
+		{name: "XMLName", value: namedPingResponse{Message: "pong"}, want: "<ping><message>pong</message></ping>"},
+		{name: "slice", value: []pingResponse{{Message: "a"}, {Message: "b"}}, want: "<response><item><message>a</message><count>0</count></item><item><message>b</message><count>0</count></item></response>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := XMLSerializer{}.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := xml.Header + tt.want; string(got) != want {
+				t.Errorf("Marshal = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestXMLResponse(t *testing.T) {
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, struct{}, testGetParams]) (*GGResponse[pingResponse, testErrorData], error) {
+		return &GGResponse[pingResponse, testErrorData]{ResponseData: &pingResponse{Message: "pong"}}, nil
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, struct{}, testGetParams, pingResponse, testErrorData](&DataProcessingMiddlewareSettings{Serializer: XMLSerializer{}})
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "application/xml" {
+		t.Errorf("Content-Type = %q, want application/xml", got)
+	}
+	var got pingResponse
+	if err := xml.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Message != "pong" {
+		t.Errorf("body = %q, %v", rec.Body.String(), err)
+	}
+}
+
+func TestYAMLResponseRoundTrip(t *testing.T) {
+	want := pingResponse{Message: "pong: yes", Count: 3}
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, struct{}, testGetParams]) (*GGResponse[pingResponse, testErrorData], error) {
+		return &GGResponse[pingResponse, testErrorData]{ResponseData: &want}, nil
+	}, discardLogger())
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, struct{}, testGetParams, pingResponse, testErrorData](&DataProcessingMiddlewareSettings{
+		Serializers: map[string]Serializer{"application/yaml": YAMLSerializer{}},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Accept", "application/yaml")
+
+	rec := serve(t, u, req)
+
+	if got := rec.Header().Get("Content-Type"); got != "application/yaml" {
+		t.Errorf("Content-Type = %q, want application/yaml", got)
+	}
+	var got pingResponse
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestMsgpackRequestAndResponse(t *testing.T) {
+	u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: ggreq.RequestData.Value}}, nil
+	})
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](&DataProcessingMiddlewareSettings{
+		Serializers:   map[string]Serializer{"application/msgpack": MsgpackSerializer{}},
+		Deserializers: map[string]Deserializer{"application/msgpack": MsgpackDeserializer{}},
+	})
+	body, err := msgpack.Marshal(map[string]string{"key": "k", "value": "v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/msgpack")
+	req.Header.Set("Accept", "application/msgpack")
+
+	rec := serve(t, u, req)
+
+	if got := rec.Header().Get("Content-Type"); got != "application/msgpack" {
+		t.Errorf("Content-Type = %q, want application/msgpack", got)
+	}
+	var got map[string]string
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &got); err != nil || got["value"] != "v" {
+		t.Errorf("response = %v, %v", got, err)
+	}
+}
+
+func BenchmarkSerializers(b *testing.B) {
+	resp := &pingResponse{Message: "pong", Count: 42}
+	for _, serializer := range []Serializer{JSONSerializer{}, MsgpackSerializer{}} {
+		b.Run(serializer.ContentType(), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if _, err := serializer.Marshal(resp); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestNDJSONStream(t *testing.T) {
+	streamErr := errors.New("storage failed")
+	tests := []struct {
+		name       string
+		items      []string
+		failAt     int
+		wantStatus int
+		wantBody   string
+		wantAbort  bool
+	}{
+		{
+			name:       "success",
+			items:      []string{"a", "b"},
+			failAt:     -1,
+			wantStatus: http.StatusOK,
+			wantBody:   "{\"value\":\"a\"}\n{\"value\":\"b\"}\n",
+		},
+		{
+			name:       "empty",
+			failAt:     -1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "error before the first item",
+			items:      []string{"a", "b"},
+			failAt:     0,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "error midway",
+			items:      []string{"a", "b"},
+			failAt:     1,
+			wantStatus: http.StatusOK,
+			wantBody:   "{\"value\":\"a\"}\n",
+			wantAbort:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				return &GGResponse[testResponse, testErrorData]{
+					Stream: func(yield func(testResponse, error) bool) {
+						for i, item := range tt.items {
+							if i == tt.failAt {
+								yield(testResponse{}, streamErr)
+								return
+							}
+							if !yield(testResponse{Value: item}, nil) {
+								return
+							}
+						}
+					},
+				}, nil
+			})
+
+			rec := httptest.NewRecorder()
+			aborted := false
+			func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						if recovered != http.ErrAbortHandler {
+							panic(recovered)
+						}
+						aborted = true
+					}
+				}()
+				u.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			}()
+
+			if aborted != tt.wantAbort {
+				t.Errorf("aborted = %v, want %v", aborted, tt.wantAbort)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			wantContentType := "application/x-ndjson"
+			if tt.wantStatus != http.StatusOK {
+				wantContentType = "text/plain; charset=utf-8"
+			}
+			if got := rec.Header().Get("Content-Type"); got != wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, wantContentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gogohandlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type serverOptions struct {
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+	drainTimeout time.Duration
+	logger       *slog.Logger
+}
+
+type ServerOption func(*serverOptions)
+
+func WithReadTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) { o.readTimeout = d }
+}
+
+func WithWriteTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) { o.writeTimeout = d }
+}
+
+func WithIdleTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) { o.idleTimeout = d }
+}
+
+// WithDrainTimeout limits how long in-flight requests are waited for on shutdown, 30s by default.
+func WithDrainTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) { o.drainTimeout = d }
+}
+
+func WithServerLogger(logger *slog.Logger) ServerOption {
+	return func(o *serverOptions) { o.logger = logger }
+}
+
+// RunServer serves the handler on addr until SIGINT or SIGTERM is received, then shuts the server
+// down gracefully, letting in-flight requests finish within the drain timeout.
+func RunServer(addr string, mux http.Handler, opts ...ServerOption) error {
+	options := &serverOptions{
+		drainTimeout: 30 * time.Second,
+		logger:       slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  options.readTimeout,
+		WriteTimeout: options.writeTimeout,
+		IdleTimeout:  options.idleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		options.logger.Info("Starting server", slog.String("addr", addr))
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	options.logger.Info("Shutting down server", slog.Duration("drain_timeout", options.drainTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), options.drainTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		options.logger.Error("Server shutdown failed", slog.String("error", err.Error()))
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	options.logger.Info("Server stopped")
+	return nil
+}
@@ -0,0 +1,40 @@
+package gogohandlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// maxSignedBodyBytes limits bodies buffered for signature verification.
+const maxSignedBodyBytes = 10 << 20
+
+// GetSignatureMiddleware verifies the HMAC-SHA256 of the raw request body against the hex digest
+// in the header, optionally prefixed with "sha256=", and answers with 401 when it's missing or
+// doesn't match. The body is buffered with BufferBody (up to 10 MB, 413 above), so it can still be
+// decoded; the middleware has to be placed outside DataProcessingMiddleware.
+func GetSignatureMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](secret []byte, header string) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("SignatureMiddleware start")
+			body, err := ggreq.BufferBody(maxSignedBodyBytes)
+			if err != nil {
+				return &GGResponse[TRespBody, TErrorData]{}, err
+			}
+
+			signature, err := hex.DecodeString(strings.TrimPrefix(ggreq.Request.Header.Get(header), "sha256="))
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(body)
+			if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+				ggreq.Logger.Info("Request signature mismatch")
+				return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{Message: "invalid signature", StatusCode: http.StatusUnauthorized}
+			}
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("SignatureMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gogohandlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	secret := []byte("webhook secret")
+	body := `{"key":"k","value":"v"}`
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      int
+	}{
+		{name: "correct", body: body, signature: signature, want: http.StatusOK},
+		{name: "correct with prefix", body: body, signature: "sha256=" + signature, want: http.StatusOK},
+		{name: "tampered body", body: `{"key":"k","value":"x"}`, signature: signature, want: http.StatusUnauthorized},
+		{name: "missing", body: body, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *testRequest
+			u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+				got = ggreq.RequestData
+				return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{}}, nil
+			}, GetSignatureMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](secret, "X-Signature"))
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set("X-Signature", tt.signature)
+			}
+
+			rec := serve(t, u, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && (got == nil || got.Value != "v") {
+				t.Errorf("request data = %+v, want the decoded body", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package gogohandlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SSEWriter sends Server-Sent Events to the client.
+type SSEWriter struct {
+	w                  http.ResponseWriter
+	responseController *http.ResponseController
+}
+
+// NewSSEWriter takes over the response writer of the request (see GGRequest.TakeOverResponseWriter)
+// and starts an event stream. The handler should return once it's done sending events, the returned
+// GGResponse is ignored.
+func NewSSEWriter[TServiceProvider ServiceProvider, TReqBody, TGetParams any](ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*SSEWriter, error) {
+	w := ggreq.TakeOverResponseWriter()
+	sseWriter := &SSEWriter{
+		w:                  w,
+		responseController: http.NewResponseController(w),
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := sseWriter.responseController.Flush(); err != nil {
+		return nil, err
+	}
+	return sseWriter, nil
+}
+
+// Send sends an event, empty event name results in the default "message" event.
+func (s *SSEWriter) Send(event, data string) error {
+	var message strings.Builder
+	if event != "" {
+		fmt.Fprintf(&message, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&message, "data: %s\n", line)
+	}
+	message.WriteString("\n")
+
+	if _, err := s.w.Write([]byte(message.String())); err != nil {
+		return err
+	}
+	return s.responseController.Flush()
+}
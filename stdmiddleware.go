@@ -0,0 +1,81 @@
+package gogohandlers
+
+import (
+	"bytes"
+	"net/http"
+)
+
+type capturingResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *capturingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *capturingResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *capturingResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// WrapHTTPMiddleware adapts a standard net/http middleware. The std middleware is run against a response
+// writer which only records what is written to it:
+//   - the request passed to the next handler replaces ggreq.Request, so context values are propagated;
+//   - the next handler runs the wrapped GG handler and writes the resolved status code, without the body;
+//   - headers set by the std middleware are added to GGResponse.Headers;
+//   - if the std middleware doesn't call the next handler, the status, headers and body it wrote are
+//     used as the response, so the middleware should be placed outside DataProcessingMiddleware.
+func WrapHTTPMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](std func(http.Handler) http.Handler) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("WrapHTTPMiddleware start")
+			var ggresp *GGResponse[TRespBody, TErrorData]
+			var err error
+			nextCalled := false
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				ggreq.Request = r
+				ggresp, err = hFunc(ggreq)
+				statusCode, _ := resolveResponse(ggresp, err)
+				w.WriteHeader(statusCode)
+			})
+			capturingWriter := &capturingResponseWriter{header: make(http.Header)}
+			std(next).ServeHTTP(capturingWriter, ggreq.Request)
+
+			if !nextCalled {
+				ggreq.Logger.Debug("WrapHTTPMiddleware finish, next handler was not called")
+				statusCode := capturingWriter.statusCode
+				if statusCode == 0 {
+					statusCode = http.StatusOK
+				}
+				return &GGResponse[TRespBody, TErrorData]{
+					StatusCode:         statusCode,
+					Headers:            capturingWriter.header,
+					serializedResponse: capturingWriter.body.Bytes(),
+				}, nil
+			}
+
+			if ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+			}
+			if len(capturingWriter.header) > 0 && ggresp.Headers == nil {
+				ggresp.Headers = make(map[string][]string)
+			}
+			for headerName, headerValues := range capturingWriter.header {
+				ggresp.Headers[headerName] = append(ggresp.Headers[headerName], headerValues...)
+			}
+
+			ggreq.Logger.Debug("WrapHTTPMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
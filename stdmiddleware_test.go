@@ -0,0 +1,56 @@
+package gogohandlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stdContextKey struct{}
+
+func TestWrapHTTPMiddlewareAddsHeaders(t *testing.T) {
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), stdContextKey{}, "from std")))
+		})
+	}
+	u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		value, _ := ggreq.Context().Value(stdContextKey{}).(string)
+		return &GGResponse[testResponse, testErrorData]{ResponseData: &testResponse{Value: value}}, nil
+	}, WrapHTTPMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](std))
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get("X-Std"); got != "1" {
+		t.Errorf("X-Std = %q, want 1", got)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"value":"from std"}` {
+		t.Errorf("response = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWrapHTTPMiddlewareShortCircuit(t *testing.T) {
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("short"))
+		})
+	}
+	called := false
+	u := newTestHandler(func(*GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		called = true
+		return nil, nil
+	}, WrapHTTPMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](std))
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if called {
+		t.Error("handler was called")
+	}
+	if rec.Code != http.StatusTeapot || rec.Body.String() != "short" || rec.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("response = %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+}
@@ -0,0 +1,57 @@
+package gogohandlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Deadline returns the deadline of the request context, set e.g. by GetTimeoutMiddleware.
+// The context is cancelled once it passes, so calls using it fail with context.DeadlineExceeded.
+func (ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) Deadline() (time.Time, bool) {
+	return ggreq.Request.Context().Deadline()
+}
+
+// RemainingBudget returns the time left until the request deadline, zero when it has passed.
+// Without a deadline the budget is unlimited and math.MaxInt64 is returned. Handlers may use
+// it to skip optional work:
+//
+//	if gogohandlers.RemainingBudget(ggreq) > 100*time.Millisecond {
+//		enrich(ggreq.Context(), resp)
+//	}
+func RemainingBudget[TServiceProvider ServiceProvider, TReqBody, TGetParams any](ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) time.Duration {
+	deadline, ok := ggreq.Deadline()
+	if !ok {
+		return math.MaxInt64
+	}
+	return max(time.Until(deadline), 0)
+}
+
+// GetTimeoutMiddleware bounds the request context with the given timeout.
+// Handlers are expected to observe cancellation through ggreq.Request.Context(),
+// when they return after the deadline the response is replaced with 504.
+func GetTimeoutMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](timeout time.Duration) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("TimeoutMiddleware start")
+			ctx, cancel := context.WithTimeout(ggreq.Request.Context(), timeout)
+			defer cancel()
+			ggreq.Request = ggreq.Request.WithContext(ctx)
+
+			ggresp, err := hFunc(ggreq)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				ggreq.Logger.Warn("Request timed out", slog.Duration("timeout", timeout))
+				return &GGResponse[TRespBody, TErrorData]{}, MiddlewareProcessingError{
+					Message:    http.StatusText(http.StatusGatewayTimeout),
+					StatusCode: http.StatusGatewayTimeout,
+				}
+			}
+
+			ggreq.Logger.Debug("TimeoutMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
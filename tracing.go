@@ -0,0 +1,63 @@
+package gogohandlers
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetTracingMiddleware starts a server span per request, continuing the trace from the incoming
+// W3C traceparent header. The span context is put into ggreq.Request, so handlers can start child
+// spans from ggreq.Request.Context(), and is returned to the client in the traceparent response header.
+func GetTracingMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](tracer trace.Tracer) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	propagator := propagation.TraceContext{}
+
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("TracingMiddleware start")
+			spanName := ggreq.Request.Pattern
+			if spanName == "" {
+				spanName = ggreq.Request.Method
+			}
+
+			ctx := propagator.Extract(ggreq.Request.Context(), propagation.HeaderCarrier(ggreq.Request.Header))
+			ctx, span := tracer.Start(
+				ctx,
+				spanName,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", ggreq.Request.Method),
+					attribute.String("http.route", ggreq.Request.Pattern),
+					attribute.String("url.path", ggreq.Request.URL.Path),
+				),
+			)
+			defer span.End()
+			ggreq.Request = ggreq.Request.WithContext(ctx)
+
+			ggresp, err := hFunc(ggreq)
+
+			statusCode, _ := resolveResponse(ggresp, err)
+			span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+			if err != nil {
+				span.RecordError(err)
+			}
+			if statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+
+			if ggresp == nil {
+				ggresp = &GGResponse[TRespBody, TErrorData]{}
+			}
+			if ggresp.Headers == nil {
+				ggresp.Headers = make(map[string][]string)
+			}
+			propagator.Inject(ctx, propagation.HeaderCarrier(ggresp.Headers))
+
+			ggreq.Logger.Debug("TracingMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
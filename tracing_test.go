@@ -0,0 +1,59 @@
+package gogohandlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	var handlerSpan trace.SpanContext
+	u := newTestHandler(func(ggreq *GGRequest[testServiceProvider, testRequest, testGetParams]) (*GGResponse[testResponse, testErrorData], error) {
+		_, child := tracer.Start(ggreq.Context(), "query")
+		defer child.End()
+		handlerSpan = child.SpanContext()
+		return nil, errors.New("query failed")
+	}, GetTracingMiddleware[testServiceProvider, testRequest, testGetParams, testResponse, testErrorData](tracer))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /items/{id}", u)
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	rec := serve(t, mux, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want the server span and the handler's child", len(spans))
+	}
+	child, server := spans[0], spans[1]
+	if server.Name != "GET /items/{id}" || server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span = %q %v", server.Name, server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the incoming one", got)
+	}
+	if got := server.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span ID = %s, want the incoming one", got)
+	}
+	if child.Parent.SpanID() != server.SpanContext.SpanID() || !child.SpanContext.Equal(handlerSpan) {
+		t.Error("handler span is not a child of the server span")
+	}
+	if server.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want error for 500", server.Status.Code)
+	}
+	if got := rec.Header().Get("traceparent"); !strings.Contains(got, server.SpanContext.SpanID().String()) {
+		t.Errorf("traceparent response header = %q, want the server span", got)
+	}
+}
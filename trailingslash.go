@@ -0,0 +1,69 @@
+package gogohandlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+type TrailingSlashMode int
+
+const (
+	// TrailingSlashRedirect redirects requests with a trailing slash to the path without it with 308.
+	TrailingSlashRedirect TrailingSlashMode = iota
+	// TrailingSlashRewrite strips the trailing slash from the request path before calling the handler.
+	TrailingSlashRewrite
+)
+
+// stripTrailingSlash removes trailing slashes of the path, "/" is kept as is.
+func stripTrailingSlash(path string) string {
+	stripped := strings.TrimRight(path, "/")
+	if stripped == "" {
+		return "/"
+	}
+	return stripped
+}
+
+// GetTrailingSlashMiddleware makes paths without a trailing slash canonical. The mux routes requests
+// before middlewares run, so it only sees requests matching the handler's pattern, e.g. a "/items/"
+// subtree one. TrailingSlashRedirect is the reliable mode: the redirected request is routed again,
+// while TrailingSlashRewrite only changes the path the handler sees, not the route. To rewrite paths
+// before routing, wrap the mux with StripTrailingSlash instead.
+func GetTrailingSlashMiddleware[TServiceProvider ServiceProvider, TReqBody, TGetParams, TRespBody, TErrorData any](mode TrailingSlashMode) func(func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+	return func(hFunc func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error)) func(*GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+		return func(ggreq *GGRequest[TServiceProvider, TReqBody, TGetParams]) (*GGResponse[TRespBody, TErrorData], error) {
+			ggreq.Logger.Debug("TrailingSlashMiddleware start")
+			path := ggreq.Request.URL.Path
+			if canonical := stripTrailingSlash(path); canonical != path {
+				if mode == TrailingSlashRedirect {
+					location := *ggreq.Request.URL
+					location.Path, location.RawPath = canonical, ""
+					ggresp := &GGResponse[TRespBody, TErrorData]{StatusCode: http.StatusPermanentRedirect}
+					ggresp.SetLocation(location.RequestURI())
+					ggreq.Logger.Debug("TrailingSlashMiddleware finish, redirected")
+					return ggresp, nil
+				}
+				ggreq.Request.URL.Path = canonical
+				ggreq.Request.URL.RawPath = ""
+			}
+
+			ggresp, err := hFunc(ggreq)
+			ggreq.Logger.Debug("TrailingSlashMiddleware finish")
+			return ggresp, err
+		}
+	}
+}
+
+// StripTrailingSlash strips trailing slashes from request paths before they are routed, so "/ping/"
+// is served by the "/ping" route. It wraps the whole mux:
+//
+//	RunServer(":8080", gogohandlers.StripTrailingSlash(mux))
+func StripTrailingSlash(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if canonical := stripTrailingSlash(r.URL.Path); canonical != r.URL.Path {
+			r2 := r.Clone(r.Context())
+			r2.URL.Path, r2.URL.RawPath = canonical, ""
+			r = r2
+		}
+		next.ServeHTTP(w, r)
+	})
+}
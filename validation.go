@@ -0,0 +1,118 @@
+package gogohandlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError reports invalid fields, mapping field names to messages.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range slices.Sorted(maps.Keys(e.Fields)) {
+		messages = append(messages, field+": "+e.Fields[field])
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ValidationErrorHandler builds an error handler rendering ValidationError with 400,
+// errorDataFunc puts the invalid fields into the error data:
+//
+//	func(fields map[string]string) *ExampleAppErrorData {
+//		return &ExampleAppErrorData{Message: "validation failed", Details: fields}
+//	}
+func ValidationErrorHandler[TErrorData any](errorDataFunc func(fields map[string]string) *TErrorData) func(err error, l *slog.Logger) (int, *TErrorData) {
+	return func(err error, l *slog.Logger) (int, *TErrorData) {
+		validationError, ok := MatchError[ValidationError](err)
+		if !ok {
+			return 0, nil
+		}
+		return validationError.StatusCode(), errorDataFunc(validationError.Fields)
+	}
+}
+
+// Validatable is implemented by request data and get params checking themselves.
+// DataProcessingMiddleware calls Validate after decoding when the Validate setting is enabled,
+// and answers with 400 when it fails. Return ValidationError to report invalid fields.
+type Validatable interface {
+	Validate() error
+}
+
+// validateSelf runs Validate of v if it is Validatable, errors other than ValidationError
+// are wrapped into a MiddlewareProcessingError with 400.
+func validateSelf(v any) error {
+	validatable, ok := v.(Validatable)
+	if !ok {
+		return nil
+	}
+	err := validatable.Validate()
+	if err == nil {
+		return nil
+	}
+	if _, ok := MatchError[ValidationError](err); ok {
+		return err
+	}
+	return MiddlewareProcessingError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+}
+
+func defaultFieldErrorFormatter(fieldError validator.FieldError) string {
+	return fmt.Sprintf("failed on the '%s' rule", fieldError.Tag())
+}
+
+// validateAll validates values with their Validate methods and `validate` struct tags. Failed fields
+// of all values are reported in a single ValidationError, other errors are returned as is.
+func validateAll(validate *validator.Validate, formatFieldError func(validator.FieldError) string, values ...any) error {
+	var fields map[string]string
+	for _, v := range values {
+		for _, err := range []error{validateSelf(v), validateStruct(validate, v, formatFieldError)} {
+			if err == nil {
+				continue
+			}
+			validationError, ok := MatchError[ValidationError](err)
+			if !ok {
+				return err
+			}
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			maps.Copy(fields, validationError.Fields)
+		}
+	}
+	if fields != nil {
+		return ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateStruct validates v with `validate` struct tags, all failed fields are reported in a single ValidationError.
+func validateStruct(validate *validator.Validate, v any, formatFieldError func(validator.FieldError) string) error {
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := validate.Struct(v)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make(map[string]string, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fields[fieldError.Namespace()] = formatFieldError(fieldError)
+		}
+		return ValidationError{Fields: fields}
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package gogohandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validatedRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type validatedGetParams struct {
+	Count int `schema:"count" validate:"min=1"`
+}
+
+type validationErrorData struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func TestValidationErrorMergesFieldsOfBodyAndGetParams(t *testing.T) {
+	u := NewHandler(&testServiceProvider{}, func(*GGRequest[testServiceProvider, validatedRequest, validatedGetParams]) (*GGResponse[testResponse, validationErrorData], error) {
+		return &GGResponse[testResponse, validationErrorData]{ResponseData: &testResponse{}}, nil
+	}, discardLogger())
+	errorHandler := ValidationErrorHandler(func(fields map[string]string) *validationErrorData {
+		return &validationErrorData{Fields: fields}
+	})
+	u.Middlewares = DefaultMiddlewares[testServiceProvider, validatedRequest, validatedGetParams, testResponse](&DataProcessingMiddlewareSettings{Validate: true}, errorHandler)
+
+	rec := serve(t, u, httptest.NewRequest(http.MethodPost, "/?count=0", strings.NewReader(`{}`)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var errorData validationErrorData
+	if err := json.Unmarshal(rec.Body.Bytes(), &errorData); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"validatedRequest.Name", "validatedGetParams.Count"} {
+		if _, ok := errorData.Fields[field]; !ok {
+			t.Errorf("fields = %v, want %s", errorData.Fields, field)
+		}
+	}
+}